@@ -2,43 +2,207 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/jwt"
 
 	"github.com/vstdy/gophermart/api/model"
 	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
 )
 
-func (h Handler) setAuthCookie(w http.ResponseWriter, obj canonical.User) error {
-	_, token, err := h.tokenAuth.Encode(model.NewJWTClaims(obj))
+const (
+	accessTokenCookie  = "jwt"
+	refreshTokenCookie = "refresh_token"
+	refreshTokenPath   = "/api/user"
+)
+
+// authorize creates a new session for given user and issues auth tokens.
+func (h Handler) authorize(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	session, err := h.service.CreateSession(r.Context(), userID, clientInfo(r))
+	if err != nil {
+		return err
+	}
+
+	return h.issueTokens(w, session)
+}
+
+// issueTokens issues a new access token and passes auth tokens to the client within enabled auth modes:
+// cookies and (or) Authorization header with JSON body.
+func (h Handler) issueTokens(w http.ResponseWriter, session canonical.Session) error {
+	accessToken := canonical.NewAccessToken(session, h.accessTokenTTL)
+
+	_, token, err := h.tokenAuth.Encode(model.NewJWTClaims(accessToken))
 	if err != nil {
-		return fmt.Errorf("auth cookie: %v", err)
+		return fmt.Errorf("access token: %v", err)
 	}
 
-	cookie := http.Cookie{
-		Name:  "jwt",
-		Value: token,
-		Path:  "/",
+	if h.cookieAuth {
+		accessCookie := h.newAuthCookie(accessTokenCookie, token)
+		accessCookie.Expires = accessToken.ExpiresAt
+		http.SetCookie(w, accessCookie)
+
+		refreshCookie := h.newAuthCookie(refreshTokenCookie, session.RefreshToken)
+		refreshCookie.Expires = session.ExpiresAt
+		http.SetCookie(w, refreshCookie)
+
+		// Refresh token is kept within HttpOnly cookie only, so it can't be read by scripts
+		session.RefreshToken = ""
+	}
+
+	if h.bearerAuth {
+		res, err := json.Marshal(model.NewTokenResponse(token, accessToken, session))
+		if err != nil {
+			return fmt.Errorf("token response: %v", err)
+		}
+
+		w.Header().Set("Authorization", "Bearer "+token)
+		w.Header().Set("Content-Type", "application/json")
+		if _, err = w.Write(res); err != nil {
+			return fmt.Errorf("token response: %v", err)
+		}
 	}
-	http.SetCookie(w, &cookie)
 
 	return nil
 }
 
-func (h Handler) getUserID(ctx context.Context) (uuid.UUID, error) {
+// issueTwoFactorChallenge passes two-factor authentication challenge token to the client within JSON body.
+func (h Handler) issueTwoFactorChallenge(w http.ResponseWriter, userID uuid.UUID) error {
+	challenge := canonical.NewTwoFactorChallenge(userID, h.challengeTTL)
+
+	_, token, err := h.tokenAuth.Encode(model.NewTwoFactorChallengeJWTClaims(challenge))
+	if err != nil {
+		return fmt.Errorf("challenge token: %v", err)
+	}
+
+	res, err := json.Marshal(model.NewTwoFactorChallengeResponse(token, h.challengeTTL))
+	if err != nil {
+		return fmt.Errorf("challenge response: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if _, err = w.Write(res); err != nil {
+		return fmt.Errorf("challenge response: %v", err)
+	}
+
+	return nil
+}
+
+// getTwoFactorChallenge verifies two-factor authentication challenge token and gets the challenge.
+func (h Handler) getTwoFactorChallenge(ctx context.Context, tokenString string) (canonical.TwoFactorChallenge, error) {
+	if tokenString == "" {
+		return canonical.TwoFactorChallenge{}, jwtauth.ErrNoTokenFound
+	}
+
+	token, err := h.tokenAuth.Decode(tokenString)
+	if err == nil {
+		err = jwt.Validate(token)
+	}
+	if err != nil {
+		return canonical.TwoFactorChallenge{}, jwtauth.ErrorReason(err)
+	}
+
+	claims, err := token.AsMap(ctx)
+	if err != nil {
+		return canonical.TwoFactorChallenge{}, err
+	}
+
+	return model.NewTwoFactorChallengeFromJWTClaims(claims)
+}
+
+// tokenFinders returns access token extractors for enabled auth modes.
+func (h Handler) tokenFinders() []func(r *http.Request) string {
+	var finders []func(r *http.Request) string
+	if h.bearerAuth {
+		finders = append(finders, jwtauth.TokenFromHeader)
+	}
+	if h.cookieAuth {
+		finders = append(finders, jwtauth.TokenFromCookie)
+	}
+
+	return finders
+}
+
+// clearAuthCookies removes auth tokens cookies.
+func (h Handler) clearAuthCookies(w http.ResponseWriter) {
+	if !h.cookieAuth {
+		return
+	}
+
+	for _, name := range []string{accessTokenCookie, refreshTokenCookie} {
+		cookie := h.newAuthCookie(name, "")
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+	}
+}
+
+// newAuthCookie creates auth token cookie with the path and attributes of given token cookie.
+// Refresh token is only sent to auth routes and never on cross-site requests.
+func (h Handler) newAuthCookie(name, value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.cookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if name == refreshTokenCookie {
+		cookie.Path = refreshTokenPath
+		cookie.SameSite = http.SameSiteStrictMode
+	}
+
+	return cookie
+}
+
+// getRefreshToken gets refresh token from cookie or request body.
+func (h Handler) getRefreshToken(r *http.Request) (string, error) {
+	if h.cookieAuth {
+		if cookie, err := r.Cookie(refreshTokenCookie); err == nil && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+
+	var bodyObj model.RefreshTokenBody
+	if err := json.NewDecoder(r.Body).Decode(&bodyObj); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return bodyObj.RefreshToken, nil
+}
+
+func (h Handler) getAccessToken(ctx context.Context) (canonical.AccessToken, error) {
 	_, claims, err := jwtauth.FromContext(ctx)
 	if err != nil {
-		return uuid.Nil, err
+		return canonical.AccessToken{}, err
 	}
-	userID, err := uuid.Parse(claims["id"].(string))
+
+	return model.NewAccessTokenFromJWTClaims(claims)
+}
+
+// getUserID gets ID of the user request is made for:
+// the one resolved by targetUser (for merchant and admin routes) or access token user.
+func (h Handler) getUserID(ctx context.Context) (uuid.UUID, error) {
+	if userID, ok := ctx.Value(ctxKeyTargetUser).(uuid.UUID); ok {
+		return userID, nil
+	}
+
+	accessToken, err := h.getAccessToken(ctx)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	return userID, nil
+	return accessToken.UserID, nil
 }
 
 func (h Handler) addOrder(ctx context.Context, userID uuid.UUID, orderID string) (canonical.Order, error) {
@@ -59,3 +223,101 @@ func (h Handler) addOrder(ctx context.Context, userID uuid.UUID, orderID string)
 
 	return dbObj, nil
 }
+
+// userRateLimitKey returns authenticated user ID as rate limit key (falls back to client IP).
+func (h Handler) userRateLimitKey(r *http.Request) string {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		return clientIP(r)
+	}
+
+	return userID.String()
+}
+
+// apiKeyRateLimitKey returns authenticated API key ID as rate limit key (falls back to client IP).
+func (h Handler) apiKeyRateLimitKey(r *http.Request) string {
+	obj, ok := r.Context().Value(ctxKeyAPIKey).(canonical.APIKey)
+	if !ok {
+		return clientIP(r)
+	}
+
+	return obj.ID.String()
+}
+
+func (h Handler) writeHealth(w http.ResponseWriter, resp model.HealthResponse) {
+	res, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != model.HealthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeProfile writes user profile response.
+func (h Handler) writeProfile(w http.ResponseWriter, obj canonical.User) {
+	res, err := json.Marshal(model.NewProfileFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeNotificationSettings writes user notification settings response.
+func (h Handler) writeNotificationSettings(w http.ResponseWriter, obj canonical.NotificationSettings) {
+	res, err := json.Marshal(model.NewNotificationSettingsFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeValidationErrors responds with 400 status and validation error details.
+func (h Handler) writeValidationErrors(w http.ResponseWriter, errs pkg.ValidationErrors) {
+	res, err := json.Marshal(model.NewErrorResponseFromValidationErrors(errs))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// setRetryAfter sets Retry-After header if err carries retry time.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var retryErr pkg.RetryAfterError
+	if errors.As(err, &retryErr) {
+		setRetryAfterHeader(w, time.Until(retryErr.RetryAt))
+	}
+}
+
+// setRetryAfterHeader sets Retry-After header in seconds.
+func setRetryAfterHeader(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+}
@@ -5,78 +5,722 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v4/stdlib"
-	"github.com/lestrrat-go/jwx/jwa"
 
 	"github.com/vstdy/gophermart/api/model"
+	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	canonical "github.com/vstdy/gophermart/model"
 	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
 	"github.com/vstdy/gophermart/service/gophermart"
 )
 
 // Handler keeps handler dependencies.
 type Handler struct {
-	service   gophermart.Service
-	tokenAuth *jwtauth.JWTAuth
+	service        gophermart.Service
+	tokenAuth      *tokenauth.TokenAuth
+	accessTokenTTL time.Duration
+	challengeTTL   time.Duration
+	cookieAuth     bool
+	cookieSecure   bool
+	bearerAuth     bool
+	readiness      *Readiness
 }
 
 // NewHandler returns a new Handler instance.
-func NewHandler(service gophermart.Service, secret string) Handler {
-	tokenAuth := jwtauth.New(jwa.HS256.String(), []byte(secret), nil)
+func NewHandler(
+	service gophermart.Service,
+	tokenAuth *tokenauth.TokenAuth,
+	config common.Config,
+	readiness *Readiness,
+) Handler {
+	return Handler{
+		service:        service,
+		tokenAuth:      tokenAuth,
+		accessTokenTTL: config.AccessTokenTTL,
+		challengeTTL:   config.ChallengeTTL,
+		cookieAuth:     config.AuthCookieEnabled,
+		cookieSecure:   config.AuthCookieSecure,
+		bearerAuth:     config.AuthBearerEnabled,
+		readiness:      readiness,
+	}
+}
+
+func (h Handler) register(w http.ResponseWriter, r *http.Request) {
+	var bodyObj model.RegisterBody
+	err := json.NewDecoder(r.Body).Decode(&bodyObj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	rawObj := bodyObj.ToCanonical()
+
+	obj, err := h.service.CreateUser(r.Context(), rawObj)
+	if err != nil {
+		if errors.Is(err, pkg.ErrAlreadyExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = h.authorize(w, r, obj.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) loginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var bodyObj model.TwoFactorLoginBody
+	err := json.NewDecoder(r.Body).Decode(&bodyObj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	challenge, err := h.getTwoFactorChallenge(r.Context(), bodyObj.ChallengeToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	obj, err := h.service.VerifyTwoFactor(r.Context(), challenge, bodyObj.Code, clientInfo(r))
+	if err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) || errors.Is(err, pkg.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, pkg.ErrAccountLocked) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusLocked)
+			return
+		}
+		if errors.Is(err, pkg.ErrTooManyAttempts) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = h.authorize(w, r, obj.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) login(w http.ResponseWriter, r *http.Request) {
+	var bodyObj model.RegisterBody
+	err := json.NewDecoder(r.Body).Decode(&bodyObj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	rawObj := bodyObj.ToCanonical()
+
+	obj, err := h.service.AuthenticateUser(r.Context(), rawObj, clientInfo(r))
+	if err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, pkg.ErrAccountLocked) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusLocked)
+			return
+		}
+		if errors.Is(err, pkg.ErrTooManyAttempts) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Auth tokens are issued only after the second factor is verified
+	if obj.TwoFactorEnabled {
+		if err = h.issueTwoFactorChallenge(w, obj.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err = h.authorize(w, r, obj.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) refreshToken(w http.ResponseWriter, r *http.Request) {
+	refreshToken, err := h.getRefreshToken(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	session, err := h.service.RefreshSession(r.Context(), refreshToken, clientInfo(r))
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = h.issueTokens(w, session); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) logout(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken, err := h.getRefreshToken(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.service.Logout(r.Context(), refreshToken, accessToken); err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.clearAuthCookies(w)
+}
+
+func (h Handler) getSessions(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	objs, err := h.service.GetSessions(r.Context(), accessToken.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(objs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	res, err := json.Marshal(model.NewSessionsFromCanonical(objs, accessToken.SessionID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) revokeSession(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = h.service.RevokeUserSession(r.Context(), accessToken.UserID, sessionID); err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Signing out the current device is the same as logout
+	if sessionID == accessToken.SessionID {
+		h.clearAuthCookies(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.DeleteUserBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.service.DeleteUser(r.Context(), accessToken, bodyObj.Password); err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.clearAuthCookies(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) changePassword(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.ChangePasswordBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = h.service.ChangePassword(r.Context(), accessToken, bodyObj.CurrentPassword, bodyObj.NewPassword)
+	if err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// All sessions are revoked, so the client gets new tokens
+	if err = h.authorize(w, r, accessToken.UserID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) requestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var bodyObj model.PasswordResetRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.service.RequestPasswordReset(r.Context(), bodyObj.Login); err != nil {
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h Handler) resetPassword(w http.ResponseWriter, r *http.Request) {
+	var bodyObj model.PasswordResetBody
+	if err := json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := h.service.ResetPassword(r.Context(), bodyObj.Token, bodyObj.NewPassword); err != nil {
+		if errors.Is(err, pkg.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.clearAuthCookies(w)
+}
+
+func (h Handler) enrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := h.service.EnrollTwoFactor(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrAlreadyExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewTwoFactorEnrollmentResponse(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) confirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.TwoFactorCodeBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	codes, err := h.service.ConfirmTwoFactor(r.Context(), userID, bodyObj.Code)
+	if err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, pkg.ErrAlreadyExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.RecoveryCodesResponse{RecoveryCodes: codes})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) disableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.DisableTwoFactorBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.service.DisableTwoFactor(r.Context(), userID, bodyObj.Password, bodyObj.Code, clientInfo(r)); err != nil {
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, pkg.ErrAccountLocked) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusLocked)
+			return
+		}
+		if errors.Is(err, pkg.ErrTooManyAttempts) {
+			setRetryAfter(w, err)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h Handler) getProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := h.service.GetProfile(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeProfile(w, obj)
+}
+
+func (h Handler) updateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.UpdateProfileBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	obj, err := h.service.UpdateProfile(r.Context(), userID, bodyObj.ToCanonical())
+	if err != nil {
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeProfile(w, obj)
+}
+
+func (h Handler) getNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := h.service.GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeNotificationSettings(w, obj)
+}
+
+func (h Handler) setNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.SetNotificationSettingsBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	obj, err := h.service.SetNotificationSettings(r.Context(), userID, bodyObj.ToCanonical(), bodyObj.CurrentPassword)
+	if err != nil {
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		if errors.Is(err, pkg.ErrWrongCredentials) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	return Handler{service: service, tokenAuth: tokenAuth}
+	h.writeNotificationSettings(w, obj)
 }
 
-func (h Handler) register(w http.ResponseWriter, r *http.Request) {
-	var bodyObj model.RegisterBody
-	err := json.NewDecoder(r.Body).Decode(&bodyObj)
+func (h Handler) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.VerifyEmailBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
-	rawObj := bodyObj.ToCanonical()
+	if err = h.service.VerifyEmail(r.Context(), userID, bodyObj.Token); err != nil {
+		if errors.Is(err, pkg.ErrInvalidToken) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	obj, err := h.service.CreateUser(r.Context(), rawObj)
+	obj, err := h.service.GetNotificationSettings(r.Context(), userID)
 	if err != nil {
-		if errors.Is(err, pkg.ErrAlreadyExists) {
-			http.Error(w, err.Error(), http.StatusConflict)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeNotificationSettings(w, obj)
+}
+
+func (h Handler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var limit, offset int
+	var unreadOnly bool
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		if offset, err = strconv.Atoi(rawOffset); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if rawUnread := query.Get("unread"); rawUnread != "" {
+		if unreadOnly, err = strconv.ParseBool(rawUnread); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	obj, err := h.service.GetNotifications(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewNotificationsResponseFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if err = h.setAuthCookie(w, obj); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
 
-func (h Handler) login(w http.ResponseWriter, r *http.Request) {
-	var bodyObj model.RegisterBody
-	err := json.NewDecoder(r.Body).Decode(&bodyObj)
+func (h Handler) markNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.MarkNotificationsReadBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
-	rawObj := bodyObj.ToCanonical()
+	// Empty list is rejected, so a client bug can't mark all notifications read
+	if len(bodyObj.IDs) == 0 && !bodyObj.All {
+		http.Error(w, "ids or all must be set", http.StatusBadRequest)
+		return
+	}
+	if bodyObj.All {
+		bodyObj.IDs = nil
+	}
 
-	obj, err := h.service.AuthenticateUser(r.Context(), rawObj)
+	unread, err := h.service.MarkNotificationsRead(r.Context(), userID, bodyObj.IDs)
 	if err != nil {
-		if errors.Is(err, pkg.ErrWrongCredentials) {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.MarkNotificationsReadResponse{Unread: unread})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if err = h.setAuthCookie(w, obj); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -252,3 +896,231 @@ func (h Handler) getUsersWithdrawals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h Handler) searchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit, offset int
+	var err error
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if rawOffset := query.Get("offset"); rawOffset != "" {
+		if offset, err = strconv.Atoi(rawOffset); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	objs, err := h.service.SearchUsers(r.Context(), query.Get("query"), limit, offset)
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewUsersFromCanonical(objs))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := h.service.FindUser(r.Context(), userID.String())
+	if err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewUserFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) addAdjustment(w http.ResponseWriter, r *http.Request) {
+	accessToken, err := h.getAccessToken(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.AddAdjustmentBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	obj, err := h.service.AddAdjustment(r.Context(), accessToken.UserID, userID, bodyObj.Amount, bodyObj.Reason)
+	if err != nil {
+		var validationErrs pkg.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			h.writeValidationErrors(w, validationErrs)
+			return
+		}
+		if errors.Is(err, pkg.ErrNonSufficientFunds) {
+			http.Error(w, err.Error(), http.StatusPaymentRequired)
+			return
+		}
+		if errors.Is(err, pkg.ErrForbidden) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewAdjustmentFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) getAdjustments(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	objs, err := h.service.GetAdjustments(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(objs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	res, err := json.Marshal(model.NewAdjustmentsFromCanonical(objs))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) setUserRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var bodyObj model.SetUserRoleBody
+	if err = json.NewDecoder(r.Body).Decode(&bodyObj); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	obj, err := h.service.SetUserRole(r.Context(), userID, canonical.Role(bodyObj.Role))
+	if err != nil {
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, pkg.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res, err := json.Marshal(model.NewUserFromCanonical(obj))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeHealth(w, model.HealthResponse{Status: model.HealthStatusOK})
+}
+
+func (h Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	checks := h.service.CheckReadiness(r.Context())
+	if h.readiness.IsShuttingDown() {
+		checks = append(checks, canonical.HealthCheck{Name: "shutdown", Err: errors.New("server is shutting down")})
+	}
+
+	h.writeHealth(w, model.NewHealthResponseFromCanonical(checks))
+}
+
+func (h Handler) jwks(w http.ResponseWriter, r *http.Request) {
+	res, err := json.Marshal(h.tokenAuth.PublicKeys())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if _, err = w.Write(res); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/api/model"
+	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
+	"github.com/vstdy/gophermart/service/gophermart"
+)
+
+// fakeService implements gophermart.Service methods used by auth handlers.
+// Calling any other method panics on the nil embedded interface.
+type fakeService struct {
+	gophermart.Service
+	user           canonical.User
+	userRole       canonical.Role
+	sessionCreated bool
+}
+
+func (s *fakeService) AuthenticateUser(_ context.Context, _ canonical.User, _ canonical.ClientInfo) (canonical.User, error) {
+	return s.user, nil
+}
+
+func (s *fakeService) CreateSession(_ context.Context, userID uuid.UUID, _ canonical.ClientInfo) (canonical.Session, error) {
+	s.sessionCreated = true
+
+	return canonical.Session{
+		ID:           uuid.New(),
+		UserID:       userID,
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}, nil
+}
+
+func (s *fakeService) ValidateAccessToken(_ context.Context, _ canonical.AccessToken) (canonical.Role, error) {
+	return s.userRole, nil
+}
+
+func newTestHandler(t *testing.T, svc gophermart.Service) Handler {
+	t.Helper()
+
+	tokenAuth, err := tokenauth.New(tokenauth.NewDefaultConfig(), "secret")
+	if err != nil {
+		t.Fatalf("tokenauth.New: %v", err)
+	}
+
+	return NewHandler(svc, tokenAuth, common.BuildDefaultConfig(), nil)
+}
+
+func TestHandlerLogin(t *testing.T) {
+	testCases := []struct {
+		name             string
+		twoFactorEnabled bool
+		wantStatus       int
+		wantChallenge    bool
+	}{
+		{
+			name:       "password only",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:             "two-factor enabled",
+			twoFactorEnabled: true,
+			wantStatus:       http.StatusAccepted,
+			wantChallenge:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &fakeService{user: canonical.User{ID: uuid.New(), TwoFactorEnabled: tc.twoFactorEnabled}}
+			h := newTestHandler(t, svc)
+
+			body := strings.NewReader(`{"login":"user","password":"password"}`)
+			r := httptest.NewRequest(http.MethodPost, "/api/user/login", body)
+			w := httptest.NewRecorder()
+			h.login(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("status: got %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if !tc.wantChallenge {
+				if !svc.sessionCreated || len(resp.Cookies()) == 0 || resp.Header.Get("Authorization") == "" {
+					t.Fatal("auth tokens are not issued")
+				}
+				return
+			}
+
+			if svc.sessionCreated {
+				t.Error("session is created before the second factor is verified")
+			}
+			if cookies := resp.Cookies(); len(cookies) != 0 {
+				t.Errorf("cookies are set: %v", cookies)
+			}
+			if header := resp.Header.Get("Authorization"); header != "" {
+				t.Errorf("Authorization header is set: %s", header)
+			}
+
+			var raw map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			for _, key := range []string{"access_token", "refresh_token"} {
+				if _, ok := raw[key]; ok {
+					t.Errorf("response contains %s", key)
+				}
+			}
+
+			challengeToken, _ := raw["challenge_token"].(string)
+			challenge, err := h.getTwoFactorChallenge(r.Context(), challengeToken)
+			if err != nil {
+				t.Fatalf("challenge token: %v", err)
+			}
+			if challenge.UserID != svc.user.ID {
+				t.Errorf("challenge user ID: got %s, want %s", challenge.UserID, svc.user.ID)
+			}
+			if challenge.ID == "" {
+				t.Error("challenge has no ID, so it can't be consumed")
+			}
+
+			// Challenge token must not be accepted as an access token
+			token, err := h.tokenAuth.Decode(challengeToken)
+			if err != nil {
+				t.Fatalf("decode challenge token: %v", err)
+			}
+			claims, err := token.AsMap(r.Context())
+			if err != nil {
+				t.Fatalf("challenge token claims: %v", err)
+			}
+			if _, err = model.NewAccessTokenFromJWTClaims(claims); err == nil {
+				t.Error("challenge token is accepted as an access token")
+			}
+		})
+	}
+}
+
+func TestHandlerIssueTokens(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cookieAuth        bool
+		bearerAuth        bool
+		wantBody          bool
+		wantRefreshInBody bool
+	}{
+		{name: "cookie", cookieAuth: true},
+		{name: "bearer", bearerAuth: true, wantBody: true, wantRefreshInBody: true},
+		{name: "cookie and bearer", cookieAuth: true, bearerAuth: true, wantBody: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t, &fakeService{})
+			h.cookieAuth = tc.cookieAuth
+			h.bearerAuth = tc.bearerAuth
+			h.cookieSecure = true
+
+			session := canonical.Session{
+				ID:           uuid.New(),
+				UserID:       uuid.New(),
+				RefreshToken: "refresh-token",
+				ExpiresAt:    time.Now().Add(time.Hour),
+			}
+
+			w := httptest.NewRecorder()
+			if err := h.issueTokens(w, session); err != nil {
+				t.Fatalf("issueTokens: %v", err)
+			}
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			cookies := resp.Cookies()
+			if tc.cookieAuth != (len(cookies) == 2) {
+				t.Fatalf("cookies: got %d", len(cookies))
+			}
+			for _, cookie := range cookies {
+				if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite == http.SameSiteDefaultMode {
+					t.Errorf("cookie %s: HttpOnly %v, Secure %v, SameSite %v",
+						cookie.Name, cookie.HttpOnly, cookie.Secure, cookie.SameSite)
+				}
+			}
+
+			var body model.TokenResponse
+			err := json.NewDecoder(resp.Body).Decode(&body)
+			if !tc.wantBody {
+				if err == nil {
+					t.Errorf("unexpected body: %+v", body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if body.AccessToken == "" {
+				t.Error("access token is missing")
+			}
+			if gotRefresh := body.RefreshToken != ""; gotRefresh != tc.wantRefreshInBody {
+				t.Errorf("refresh token in body: got %v, want %v", gotRefresh, tc.wantRefreshInBody)
+			}
+		})
+	}
+}
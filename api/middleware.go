@@ -2,9 +2,42 @@ package api
 
 import (
 	"compress/gzip"
+	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/audit"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/ratelimit"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	ctxKeyAPIKey     = pkg.ContextKey("api_key")
+	ctxKeyTargetUser = pkg.ContextKey("target_user")
+	ctxKeyUserRole   = pkg.ContextKey("user_role")
 )
 
 type gzipResponseWriter struct {
@@ -54,3 +87,370 @@ func gzipCompressResponse(next http.Handler) http.Handler {
 
 	return http.HandlerFunc(fn)
 }
+
+// rateLimit rejects requests exceeding the limiter rate for the key returned by keyFn.
+func rateLimit(limiter *ratelimit.Limiter, keyFn func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(keyFn(r))
+			if !allowed {
+				setRetryAfterHeader(w, retryAfter)
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// realIP sets request remote address to the client IP forwarded by trusted proxies:
+// the rightmost X-Forwarded-For address not belonging to them (X-Real-IP if X-Forwarded-For is missing).
+// Forwarded headers of requests made by other peers are ignored, so clients can't spoof their IP.
+func realIP(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	trusted := func(ip net.IP) bool {
+		for _, ipNet := range trustedProxies {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if peer := net.ParseIP(clientIP(r)); peer != nil && trusted(peer) {
+				if ip := forwardedIP(r, trusted); ip != nil {
+					r.RemoteAddr = ip.String()
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// forwardedIP returns client IP forwarded by trusted proxies, nil if it's missing or malformed.
+func forwardedIP(r *http.Request, trusted func(ip net.IP) bool) net.IP {
+	values := r.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+	}
+
+	// Each proxy appends its peer address, so addresses are walked from the closest proxy
+	addrs := strings.Split(strings.Join(values, ","), ",")
+	var ip net.IP
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if ip = net.ParseIP(strings.TrimSpace(addrs[i])); ip == nil || !trusted(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// clientIP returns request client IP (expects realIP to be applied).
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// clientInfo returns request client data (expects realIP to be applied).
+func clientInfo(r *http.Request) canonical.ClientInfo {
+	return canonical.ClientInfo{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// httpMetrics collects HTTP requests count and latency per route.
+func httpMetrics(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		metrics.HTTPRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// traceRequest starts a server span continuing the W3C trace-context passed with request headers.
+func traceRequest(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(
+			ctx,
+			r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(tracing.InstrumentationName, "", r)...),
+		)
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if route := chi.RouteContext(ctx).RoutePattern(); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// routeHook adds matched route to the log events.
+// Route is resolved at event time as it's unknown until routing is done.
+type routeHook struct {
+	rctx *chi.Context
+}
+
+// Run implements zerolog.Hook interface.
+func (h routeHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if route := h.rctx.RoutePattern(); route != "" {
+		e.Str(logging.RouteKey, route)
+	}
+}
+
+// requestLogger puts request scoped logger to the request context and logs handled requests.
+func requestLogger(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		logCtx := log.With().
+			Str(logging.RequestIDKey, middleware.GetReqID(r.Context())).
+			Str(logging.MethodKey, r.Method).
+			Str(logging.PathKey, r.URL.Path).
+			Str(logging.RemoteIPKey, clientIP(r))
+		if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.HasTraceID() {
+			logCtx = logCtx.Str(logging.TraceIDKey, spanCtx.TraceID().String())
+		}
+		logger := logCtx.Logger()
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			logger = logger.Hook(routeHook{rctx: rctx})
+		}
+
+		ctx := logger.WithContext(r.Context())
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		zerolog.Ctx(ctx).Info().
+			Int(logging.StatusKey, status).
+			Int(logging.BytesKey, ww.BytesWritten()).
+			Dur(logging.RequestDurKey, time.Since(start)).
+			Msg("request handled")
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// auditRequest puts request ID to the request context for audit log entries.
+func auditRequest(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRequestID(r.Context(), middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// userLogger adds authenticated user ID to the request scoped logger.
+// Expects requestLogger to be applied.
+func (h Handler) userLogger(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := zerolog.Ctx(ctx)
+		if userID, err := h.getUserID(ctx); err == nil && logger != zerolog.DefaultContextLogger {
+			logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str(logging.UserIDKey, userID.String())
+			})
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// verifyToken verifies access token found by one of finders and stores the result
+// within jwtauth context, so jwtauth.Authenticator and jwtauth.FromContext can be used downstream.
+func verifyToken(auth *tokenauth.TokenAuth, finders ...func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var tokenString string
+			for _, finder := range finders {
+				if tokenString = finder(r); tokenString != "" {
+					break
+				}
+			}
+
+			var token jwt.Token
+			err := jwtauth.ErrNoTokenFound
+			if tokenString != "" {
+				token, err = auth.Decode(tokenString)
+				if err == nil {
+					err = jwt.Validate(token)
+				}
+				if err != nil {
+					err = jwtauth.ErrorReason(err)
+				}
+			}
+
+			ctx := jwtauth.NewContext(r.Context(), token, err)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// validateAccessToken rejects malformed and revoked access tokens
+// and sets the token user as audit actor.
+// Expects jwtauth.Authenticator to be applied.
+func (h Handler) validateAccessToken(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		accessToken, err := h.getAccessToken(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		role, err := h.service.ValidateAccessToken(r.Context(), accessToken)
+		if err != nil {
+			if errors.Is(err, pkg.ErrInvalidToken) {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ctx := audit.WithActor(r.Context(), canonical.Actor{Type: canonical.ActorUser, ID: accessToken.UserID.String()})
+		ctx = context.WithValue(ctx, ctxKeyUserRole, role)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// requireRole rejects requests of users not having one of given roles.
+// Expects validateAccessToken to be applied: current user role is checked, not the access token one.
+func (h Handler) requireRole(roles ...canonical.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			userRole, ok := r.Context().Value(ctxKeyUserRole).(canonical.Role)
+			if !ok {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			for _, role := range roles {
+				if userRole == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// authenticateAPIKey rejects requests without active merchant API key,
+// stores the key within request context and sets it as audit actor.
+func (h Handler) authenticateAPIKey(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		obj, err := h.service.AuthenticateAPIKey(r.Context(), r.Header.Get(apiKeyHeader))
+		if err != nil {
+			if errors.Is(err, pkg.ErrInvalidToken) {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKeyAPIKey, obj)
+		ctx = audit.WithActor(ctx, canonical.Actor{Type: canonical.ActorAPIKey, ID: obj.ID.String()})
+		if logger := zerolog.Ctx(ctx); logger != zerolog.DefaultContextLogger {
+			logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str(logging.APIKeyIDKey, obj.ID.String())
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// requireScope rejects requests made with merchant API key not granted given scope.
+// Expects authenticateAPIKey to be applied.
+func (h Handler) requireScope(scope canonical.APIKeyScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			obj, ok := r.Context().Value(ctxKeyAPIKey).(canonical.APIKey)
+			if !ok || !obj.HasScope(scope) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// targetUser resolves user the merchant (admin) acts on behalf of by ID or login from the route
+// and stores the user ID within request context, so user handlers can be reused.
+func (h Handler) targetUser(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		obj, err := h.service.FindUser(r.Context(), chi.URLParam(r, "user"))
+		if err != nil {
+			if errors.Is(err, pkg.ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKeyTargetUser, obj.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
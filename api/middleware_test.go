@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/api/model"
+	canonical "github.com/vstdy/gophermart/model"
+)
+
+func TestHandlerRequireRole(t *testing.T) {
+	testCases := []struct {
+		name       string
+		tokenRole  canonical.Role
+		userRole   canonical.Role
+		wantStatus int
+	}{
+		{name: "admin", tokenRole: canonical.RoleAdmin, userRole: canonical.RoleAdmin, wantStatus: http.StatusOK},
+		{name: "demoted admin", tokenRole: canonical.RoleAdmin, userRole: canonical.RoleUser, wantStatus: http.StatusForbidden},
+		{name: "promoted user", tokenRole: canonical.RoleUser, userRole: canonical.RoleAdmin, wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newTestHandler(t, &fakeService{userRole: tc.userRole})
+
+			session := canonical.Session{ID: uuid.New(), UserID: uuid.New(), UserRole: tc.tokenRole}
+			accessToken := canonical.NewAccessToken(session, time.Hour)
+			_, tokenString, err := h.tokenAuth.Encode(model.NewJWTClaims(accessToken))
+			if err != nil {
+				t.Fatalf("encode access token: %v", err)
+			}
+			token, err := h.tokenAuth.Decode(tokenString)
+			if err != nil {
+				t.Fatalf("decode access token: %v", err)
+			}
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			mw := h.validateAccessToken(h.requireRole(canonical.RoleAdmin)(next))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			r = r.WithContext(jwtauth.NewContext(r.Context(), token, nil))
+			w := httptest.NewRecorder()
+			mw.ServeHTTP(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tc.wantStatus)
+			}
+		})
+	}
+
+	// Role is only known after the access token is validated
+	h := newTestHandler(t, &fakeService{})
+	w := httptest.NewRecorder()
+	h.requireRole(canonical.RoleAdmin)(http.NotFoundHandler()).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without validated access token: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{
+			name:       "spoofed by client",
+			remoteAddr: "203.0.113.7:5000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forwarded by proxy",
+			remoteAddr: "10.0.0.2:5000",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.3"},
+			want:       "203.0.113.7",
+		},
+		{name: "real IP", remoteAddr: "10.0.0.2:5000", headers: map[string]string{"X-Real-IP": "203.0.113.7"}, want: "203.0.113.7"},
+		{
+			name:       "malformed",
+			remoteAddr: "10.0.0.2:5000",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, unknown"},
+			want:       "10.0.0.2",
+		},
+		{name: "no headers", remoteAddr: "10.0.0.2:5000", want: "10.0.0.2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			handler := realIP([]*net.IPNet{proxies})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientIP(r)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for name, value := range tc.headers {
+				r.Header.Set(name, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tc.want {
+				t.Errorf("client IP: got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"github.com/vstdy/gophermart/pkg"
+)
+
+type (
+	ErrorDetail struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	ErrorResponse struct {
+		Error   string        `json:"error"`
+		Details []ErrorDetail `json:"details,omitempty"`
+	}
+)
+
+// NewErrorResponseFromValidationErrors creates a new ErrorResponse object from validation errors.
+func NewErrorResponseFromValidationErrors(errs pkg.ValidationErrors) ErrorResponse {
+	resp := ErrorResponse{
+		Error:   pkg.ErrInvalidInput.Error(),
+		Details: make([]ErrorDetail, 0, len(errs)),
+	}
+	for _, err := range errs {
+		resp.Details = append(resp.Details, ErrorDetail{
+			Field:   err.Field,
+			Code:    err.Code,
+			Message: err.Message,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"github.com/vstdy/gophermart/model"
+)
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+type (
+	HealthCheck struct {
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	HealthResponse struct {
+		Status string                 `json:"status"`
+		Checks map[string]HealthCheck `json:"checks,omitempty"`
+	}
+)
+
+// NewHealthResponseFromCanonical creates a new HealthResponse object from canonical models.
+func NewHealthResponseFromCanonical(objs []model.HealthCheck) HealthResponse {
+	resp := HealthResponse{
+		Status: HealthStatusOK,
+		Checks: make(map[string]HealthCheck, len(objs)),
+	}
+	for _, obj := range objs {
+		check := HealthCheck{Status: HealthStatusOK}
+		if obj.Err != nil {
+			check = HealthCheck{Status: HealthStatusFail, Error: obj.Err.Error()}
+			resp.Status = HealthStatusFail
+		}
+		resp.Checks[obj.Name] = check
+	}
+
+	return resp
+}
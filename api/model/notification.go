@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type NotificationSettings struct {
+	Email              string          `json:"email"`
+	EmailVerified      bool            `json:"email_verified"`
+	EmailNotifications map[string]bool `json:"email_notifications"`
+}
+
+// SetNotificationSettingsBody keeps notification settings, current password is required to change email.
+type SetNotificationSettingsBody struct {
+	NotificationSettings
+	CurrentPassword string `json:"current_password"`
+}
+
+// VerifyEmailBody keeps email verification token.
+type VerifyEmailBody struct {
+	Token string `json:"token"`
+}
+
+// NewNotificationSettingsFromCanonical creates a new NotificationSettings object from canonical model.
+func NewNotificationSettingsFromCanonical(obj model.NotificationSettings) NotificationSettings {
+	settings := NotificationSettings{
+		Email:              obj.Email,
+		EmailVerified:      obj.EmailVerified,
+		EmailNotifications: make(map[string]bool, len(obj.EmailEnabled)),
+	}
+	for notificationType, enabled := range obj.EmailEnabled {
+		settings.EmailNotifications[string(notificationType)] = enabled
+	}
+
+	return settings
+}
+
+// ToCanonical converts a API model to canonical model.
+func (s NotificationSettings) ToCanonical() model.NotificationSettings {
+	obj := model.NotificationSettings{
+		Email:        s.Email,
+		EmailEnabled: make(map[model.NotificationType]bool, len(s.EmailNotifications)),
+	}
+	for notificationType, enabled := range s.EmailNotifications {
+		obj.EmailEnabled[model.NotificationType(notificationType)] = enabled
+	}
+
+	return obj
+}
+
+type Notification struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	Read      bool            `json:"read"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type NotificationsResponse struct {
+	Total         int            `json:"total"`
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// NewNotificationsResponseFromCanonical creates a new NotificationsResponse object from canonical model.
+func NewNotificationsResponseFromCanonical(obj model.NotificationList) NotificationsResponse {
+	resp := NotificationsResponse{
+		Total:         obj.Total,
+		Unread:        obj.Unread,
+		Notifications: make([]Notification, 0, len(obj.Notifications)),
+	}
+	for _, notification := range obj.Notifications {
+		resp.Notifications = append(resp.Notifications, Notification{
+			ID:        notification.ID,
+			Type:      string(notification.Type),
+			Message:   notification.Message,
+			Data:      notification.Data,
+			Read:      !notification.ReadAt.IsZero(),
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+// MarkNotificationsReadBody selects notifications to mark read: listed ones or all of them.
+type MarkNotificationsReadBody struct {
+	IDs []uuid.UUID `json:"ids"`
+	All bool        `json:"all"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Unread int `json:"unread"`
+}
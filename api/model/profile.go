@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type Profile struct {
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	Birthday    string    `json:"birthday"`
+	Language    string    `json:"language"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewProfileFromCanonical creates a new Profile object from canonical model.
+func NewProfileFromCanonical(obj model.User) Profile {
+	profile := Profile{
+		Login:       obj.Login,
+		DisplayName: obj.DisplayName,
+		Email:       obj.Email,
+		Phone:       obj.Phone,
+		Language:    obj.Language,
+		UpdatedAt:   obj.UpdatedAt,
+	}
+	if !obj.Birthday.IsZero() {
+		profile.Birthday = obj.Birthday.Format(model.BirthdayLayout)
+	}
+
+	return profile
+}
+
+// UpdateProfileBody keeps profile changes: omitted (or null) fields are kept unchanged, empty ones are cleared.
+type UpdateProfileBody struct {
+	DisplayName *string `json:"display_name"`
+	Email       *string `json:"email"`
+	Phone       *string `json:"phone"`
+	Birthday    *string `json:"birthday"`
+	Language    *string `json:"language"`
+}
+
+// ToCanonical converts a API model to canonical model.
+func (b UpdateProfileBody) ToCanonical() model.ProfileUpdate {
+	return model.ProfileUpdate{
+		DisplayName: b.DisplayName,
+		Email:       b.Email,
+		Phone:       b.Phone,
+		Birthday:    b.Birthday,
+		Language:    b.Language,
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewSessionsFromCanonical creates new Session objects from canonical models
+// marking the one access token is issued for as current.
+func NewSessionsFromCanonical(objs []model.Session, currentID uuid.UUID) []Session {
+	sessions := make([]Session, 0, len(objs))
+	for _, obj := range objs {
+		sessions = append(sessions, Session{
+			ID:         obj.ID,
+			IP:         obj.IP,
+			UserAgent:  obj.UserAgent,
+			Current:    obj.ID == currentID,
+			CreatedAt:  obj.CreatedAt,
+			LastUsedAt: obj.UpdatedAt,
+			ExpiresAt:  obj.ExpiresAt,
+		})
+	}
+
+	return sessions
+}
@@ -73,3 +73,38 @@ func (w GetWithdrawal) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(getWithdrawal)
 }
+
+type AddAdjustmentBody struct {
+	// Amount is positive for credit and negative for debit
+	Amount float32 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+type Adjustment struct {
+	ID          uuid.UUID `json:"id"`
+	Amount      float32   `json:"amount"`
+	Reason      string    `json:"reason"`
+	OperatorID  uuid.UUID `json:"operator_id"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// NewAdjustmentFromCanonical creates a new Adjustment object from canonical model.
+func NewAdjustmentFromCanonical(obj model.Transaction) Adjustment {
+	return Adjustment{
+		ID:          obj.ID,
+		Amount:      obj.Accrual - obj.Withdrawal,
+		Reason:      obj.Reason,
+		OperatorID:  obj.OperatorID,
+		ProcessedAt: obj.ProcessedAt,
+	}
+}
+
+// NewAdjustmentsFromCanonical creates new list of Adjustment objects from list of canonical models.
+func NewAdjustmentsFromCanonical(objs []model.Transaction) []Adjustment {
+	adjustments := make([]Adjustment, 0, len(objs))
+	for _, obj := range objs {
+		adjustments = append(adjustments, NewAdjustmentFromCanonical(obj))
+	}
+
+	return adjustments
+}
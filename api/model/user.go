@@ -1,9 +1,28 @@
 package model
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
 	"github.com/vstdy/gophermart/model"
 )
 
+const (
+	jwtClaimUserID    = "id"
+	jwtClaimRole      = "role"
+	jwtClaimSessionID = "sid"
+	jwtClaimID        = "jti"
+	jwtClaimIssuedAt  = "iat"
+	jwtClaimExpiresAt = "exp"
+	jwtClaimSubject   = "sub"
+	jwtClaimType      = "typ"
+
+	// jwtTypeTwoFactorChallenge marks tokens proving password authentication of users with 2FA enabled.
+	jwtTypeTwoFactorChallenge = "2fa_challenge"
+)
+
 type RegisterBody struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
@@ -19,8 +38,227 @@ func (b RegisterBody) ToCanonical() model.User {
 	return obj
 }
 
-func NewJWTClaims(obj model.User) map[string]interface{} {
+type RefreshTokenBody struct {
+	// RefreshToken is omitted if passed within cookie
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type DeleteUserBody struct {
+	Password string `json:"password"`
+}
+
+type ChangePasswordBody struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type PasswordResetRequestBody struct {
+	Login string `json:"login"`
+}
+
+type PasswordResetBody struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type SetUserRoleBody struct {
+	Role string `json:"role"`
+}
+
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	Login     string    `json:"login"`
+	Role      string    `json:"role"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewUserFromCanonical creates a new User object from canonical model.
+func NewUserFromCanonical(obj model.User) User {
+	return User{
+		ID:        obj.ID,
+		Login:     obj.Login,
+		Role:      string(obj.Role),
+		Email:     obj.Email,
+		CreatedAt: obj.CreatedAt,
+	}
+}
+
+// NewUsersFromCanonical creates new User objects from canonical models.
+func NewUsersFromCanonical(objs []model.User) []User {
+	users := make([]User, 0, len(objs))
+	for _, obj := range objs {
+		users = append(users, NewUserFromCanonical(obj))
+	}
+
+	return users
+}
+
+type TwoFactorCodeBody struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorBody struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type TwoFactorLoginBody struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// NewTwoFactorEnrollmentResponse creates a new TwoFactorEnrollmentResponse object from canonical model.
+func NewTwoFactorEnrollmentResponse(obj model.TwoFactorEnrollment) TwoFactorEnrollmentResponse {
+	return TwoFactorEnrollmentResponse{
+		Secret:          obj.Secret,
+		ProvisioningURI: obj.ProvisioningURI,
+	}
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+// NewTwoFactorChallengeResponse creates a new TwoFactorChallengeResponse object from signed challenge token.
+func NewTwoFactorChallengeResponse(token string, ttl time.Duration) TwoFactorChallengeResponse {
+	return TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int(ttl.Seconds()),
+	}
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewTokenResponse creates a new TokenResponse object from signed access token and canonical models.
+func NewTokenResponse(token string, accessToken model.AccessToken, session model.Session) TokenResponse {
+	return TokenResponse{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessToken.ExpiresAt.Sub(accessToken.IssuedAt).Seconds()),
+		RefreshToken: session.RefreshToken,
+	}
+}
+
+// NewJWTClaims creates JWT claims from access token canonical model.
+func NewJWTClaims(obj model.AccessToken) map[string]interface{} {
+	return map[string]interface{}{
+		jwtClaimUserID:    obj.UserID,
+		jwtClaimRole:      obj.Role,
+		jwtClaimSessionID: obj.SessionID,
+		jwtClaimID:        obj.ID,
+		jwtClaimIssuedAt:  obj.IssuedAt,
+		jwtClaimExpiresAt: obj.ExpiresAt,
+	}
+}
+
+// NewTwoFactorChallengeJWTClaims creates JWT claims of two-factor authentication challenge.
+// Challenge tokens have no access token claims, so they can't be used as ones.
+func NewTwoFactorChallengeJWTClaims(obj model.TwoFactorChallenge) map[string]interface{} {
 	return map[string]interface{}{
-		"id": obj.ID,
+		jwtClaimSubject:   obj.UserID.String(),
+		jwtClaimType:      jwtTypeTwoFactorChallenge,
+		jwtClaimID:        obj.ID,
+		jwtClaimIssuedAt:  time.Now().UTC().Truncate(time.Second),
+		jwtClaimExpiresAt: obj.ExpiresAt,
+	}
+}
+
+// NewTwoFactorChallengeFromJWTClaims creates two-factor authentication challenge canonical model
+// from verified JWT claims.
+func NewTwoFactorChallengeFromJWTClaims(claims map[string]interface{}) (model.TwoFactorChallenge, error) {
+	if typ, _ := claims[jwtClaimType].(string); typ != jwtTypeTwoFactorChallenge {
+		return model.TwoFactorChallenge{}, fmt.Errorf("%s claim: not a two-factor challenge", jwtClaimType)
+	}
+
+	rawUserID, _ := claims[jwtClaimSubject].(string)
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return model.TwoFactorChallenge{}, fmt.Errorf("%s claim: %w", jwtClaimSubject, err)
 	}
+
+	// Challenges without ID can't be consumed, so they are rejected
+	id, _ := claims[jwtClaimID].(string)
+	if id == "" {
+		return model.TwoFactorChallenge{}, fmt.Errorf("%s claim: missing", jwtClaimID)
+	}
+
+	expiresAt, ok := claims[jwtClaimExpiresAt].(time.Time)
+	if !ok {
+		return model.TwoFactorChallenge{}, fmt.Errorf("%s claim: missing", jwtClaimExpiresAt)
+	}
+
+	return model.TwoFactorChallenge{
+		ID:        id,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// NewAccessTokenFromJWTClaims creates access token canonical model from verified JWT claims.
+func NewAccessTokenFromJWTClaims(claims map[string]interface{}) (model.AccessToken, error) {
+	if typ, _ := claims[jwtClaimType].(string); typ != "" {
+		return model.AccessToken{}, fmt.Errorf("%s claim: not an access token", jwtClaimType)
+	}
+
+	rawUserID, _ := claims[jwtClaimUserID].(string)
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return model.AccessToken{}, fmt.Errorf("%s claim: %w", jwtClaimUserID, err)
+	}
+
+	id, _ := claims[jwtClaimID].(string)
+	if id == "" {
+		return model.AccessToken{}, fmt.Errorf("%s claim: missing", jwtClaimID)
+	}
+
+	expiresAt, ok := claims[jwtClaimExpiresAt].(time.Time)
+	if !ok {
+		return model.AccessToken{}, fmt.Errorf("%s claim: missing", jwtClaimExpiresAt)
+	}
+
+	issuedAt, _ := claims[jwtClaimIssuedAt].(time.Time)
+
+	// Tokens issued before sessions were bound to them have no session ID
+	var sessionID uuid.UUID
+	if rawSessionID, _ := claims[jwtClaimSessionID].(string); rawSessionID != "" {
+		if sessionID, err = uuid.Parse(rawSessionID); err != nil {
+			return model.AccessToken{}, fmt.Errorf("%s claim: %w", jwtClaimSessionID, err)
+		}
+	}
+
+	// Tokens issued before roles were introduced belong to regular users
+	role := model.RoleUser
+	if rawRole, _ := claims[jwtClaimRole].(string); rawRole != "" {
+		role = model.Role(rawRole)
+		if err = role.Validate(); err != nil {
+			return model.AccessToken{}, fmt.Errorf("%s claim: %w", jwtClaimRole, err)
+		}
+	}
+
+	return model.AccessToken{
+		ID:        id,
+		UserID:    userID,
+		SessionID: sessionID,
+		Role:      role,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+	}, nil
 }
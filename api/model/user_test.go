@@ -0,0 +1,97 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// roundTripClaims signs claims and returns them as decoded from the token.
+func roundTripClaims(t *testing.T, claims map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	_, tokenString, err := tokenAuth.Encode(claims)
+	if err != nil {
+		t.Fatalf("encoding token: %v", err)
+	}
+
+	token, err := tokenAuth.Decode(tokenString)
+	if err != nil {
+		t.Fatalf("decoding token: %v", err)
+	}
+
+	decoded, err := token.AsMap(context.Background())
+	if err != nil {
+		t.Fatalf("token claims: %v", err)
+	}
+
+	return decoded
+}
+
+func TestAccessTokenJWTClaims(t *testing.T) {
+	session := model.Session{ID: uuid.New(), UserID: uuid.New(), UserRole: model.RoleAdmin}
+	accessToken := model.NewAccessToken(session, time.Hour)
+
+	got, err := NewAccessTokenFromJWTClaims(roundTripClaims(t, NewJWTClaims(accessToken)))
+	if err != nil {
+		t.Fatalf("NewAccessTokenFromJWTClaims: %v", err)
+	}
+	if got.ID != accessToken.ID || got.UserID != accessToken.UserID || got.SessionID != accessToken.SessionID ||
+		got.Role != accessToken.Role || !got.ExpiresAt.Equal(accessToken.ExpiresAt) {
+		t.Errorf("access token: got %+v, want %+v", got, accessToken)
+	}
+
+	if _, err = NewTwoFactorChallengeFromJWTClaims(roundTripClaims(t, NewJWTClaims(accessToken))); err == nil {
+		t.Error("access token accepted as two-factor challenge")
+	}
+}
+
+func TestAccessTokenJWTClaimsLegacy(t *testing.T) {
+	userID := uuid.New()
+	claims := roundTripClaims(t, map[string]interface{}{
+		jwtClaimUserID:    userID,
+		jwtClaimID:        uuid.NewString(),
+		jwtClaimExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	got, err := NewAccessTokenFromJWTClaims(claims)
+	if err != nil {
+		t.Fatalf("NewAccessTokenFromJWTClaims: %v", err)
+	}
+	if got.UserID != userID || got.SessionID != uuid.Nil || got.Role != model.RoleUser {
+		t.Errorf("access token: got %+v", got)
+	}
+
+	claims[jwtClaimRole] = "root"
+	if _, err = NewAccessTokenFromJWTClaims(claims); err == nil {
+		t.Error("unknown role accepted")
+	}
+}
+
+func TestTwoFactorChallengeJWTClaims(t *testing.T) {
+	challenge := model.NewTwoFactorChallenge(uuid.New(), 5*time.Minute)
+
+	got, err := NewTwoFactorChallengeFromJWTClaims(roundTripClaims(t, NewTwoFactorChallengeJWTClaims(challenge)))
+	if err != nil {
+		t.Fatalf("NewTwoFactorChallengeFromJWTClaims: %v", err)
+	}
+	if got.ID != challenge.ID || got.UserID != challenge.UserID || !got.ExpiresAt.Equal(challenge.ExpiresAt) {
+		t.Errorf("challenge: got %+v, want %+v", got, challenge)
+	}
+
+	if _, err = NewAccessTokenFromJWTClaims(roundTripClaims(t, NewTwoFactorChallengeJWTClaims(challenge))); err == nil {
+		t.Error("two-factor challenge accepted as access token")
+	}
+
+	claims := roundTripClaims(t, NewTwoFactorChallengeJWTClaims(challenge))
+	delete(claims, jwtClaimID)
+	if _, err = NewTwoFactorChallengeFromJWTClaims(claims); err == nil {
+		t.Error("challenge without ID accepted")
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"sync/atomic"
+)
+
+// Readiness keeps server readiness state.
+type Readiness struct {
+	shuttingDown int32
+}
+
+// NewReadiness returns a new Readiness instance.
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// SetShuttingDown marks server as shutting down, so readiness probe starts failing.
+func (r *Readiness) SetShuttingDown() {
+	atomic.StoreInt32(&r.shuttingDown, 1)
+}
+
+// IsShuttingDown reports whether server is shutting down.
+func (r *Readiness) IsShuttingDown() bool {
+	return atomic.LoadInt32(&r.shuttingDown) == 1
+}
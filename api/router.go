@@ -6,38 +6,100 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 
 	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/ratelimit"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
 	"github.com/vstdy/gophermart/service/gophermart"
 )
 
 // NewRouter returns router.
-func NewRouter(svc gophermart.Service, config common.Config) chi.Router {
-	h := NewHandler(svc, config.SecretKey)
+func NewRouter(
+	svc gophermart.Service,
+	tokenAuth *tokenauth.TokenAuth,
+	config common.Config,
+	readiness *Readiness,
+) chi.Router {
+	h := NewHandler(svc, tokenAuth, config, readiness)
 	r := chi.NewRouter()
 
+	// Trusted proxies are validated on config setup
+	trustedProxies, _ := config.TrustedProxyNets()
+
 	r.Use(
 		middleware.RequestID,
-		middleware.RealIP,
-		middleware.Logger,
+		auditRequest,
+		realIP(trustedProxies),
+		traceRequest,
+		requestLogger,
 		middleware.Recoverer,
+		httpMetrics,
 		middleware.StripSlashes,
 		middleware.Timeout(config.Timeout),
 		gzipDecompressRequest,
 		gzipCompressResponse,
 	)
 
+	r.Get("/healthz", h.healthz)
+	r.Get("/readyz", h.readyz)
+	r.Get("/.well-known/jwks.json", h.jwks)
+
+	if config.Metrics.Enabled && config.Metrics.Address == "" {
+		r.Handle(metrics.Path, metrics.Handler())
+	}
+
 	r.Route("/api/user", func(r chi.Router) {
 		// Public routes
 		r.Group(func(r chi.Router) {
+			if config.RateLimit.Enabled {
+				limiter := ratelimit.NewLimiter(config.RateLimit.PublicLimit(), config.RateLimit.IdleTTL)
+				r.Use(rateLimit(limiter, clientIP))
+			}
 			r.Use(middleware.AllowContentType("application/json"))
 
 			r.Post("/register", h.register)
 			r.Post("/login", h.login)
+			r.Post("/login/2fa", h.loginTwoFactor)
+			r.Post("/token/refresh", h.refreshToken)
+			r.Post("/password/reset", h.requestPasswordReset)
+			r.Post("/password/reset/confirm", h.resetPassword)
 		})
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
-			r.Use(jwtauth.Verifier(h.tokenAuth))
+			r.Use(verifyToken(h.tokenAuth, h.tokenFinders()...))
 			r.Use(jwtauth.Authenticator)
+			r.Use(h.validateAccessToken)
+			r.Use(h.userLogger)
+			if config.RateLimit.Enabled {
+				limiter := ratelimit.NewLimiter(config.RateLimit.ProtectedLimit(), config.RateLimit.IdleTTL)
+				r.Use(rateLimit(limiter, h.userRateLimitKey))
+			}
+
+			r.Delete("/", h.deleteUser)
+			r.Post("/logout", h.logout)
+			r.Post("/password", h.changePassword)
+			r.Post("/2fa/enroll", h.enrollTwoFactor)
+			r.Post("/2fa/confirm", h.confirmTwoFactor)
+			r.Post("/2fa/disable", h.disableTwoFactor)
+
+			r.Route("/sessions", func(r chi.Router) {
+				r.Get("/", h.getSessions)
+				r.Delete("/{id}", h.revokeSession)
+			})
+
+			r.Route("/profile", func(r chi.Router) {
+				r.Get("/", h.getProfile)
+				r.Patch("/", h.updateProfile)
+			})
+
+			r.Route("/notifications", func(r chi.Router) {
+				r.Get("/", h.getNotifications)
+				r.Post("/read", h.markNotificationsRead)
+				r.Get("/settings", h.getNotificationSettings)
+				r.Put("/settings", h.setNotificationSettings)
+				r.Post("/email/verify", h.verifyEmail)
+			})
 
 			r.Route("/orders", func(r chi.Router) {
 				r.Post("/", h.addUsersOrder)
@@ -52,5 +114,54 @@ func NewRouter(svc gophermart.Service, config common.Config) chi.Router {
 		})
 	})
 
+	r.Route("/api/merchant", func(r chi.Router) {
+		r.Use(h.authenticateAPIKey)
+		if config.RateLimit.Enabled {
+			limiter := ratelimit.NewLimiter(config.RateLimit.ProtectedLimit(), config.RateLimit.IdleTTL)
+			r.Use(rateLimit(limiter, h.apiKeyRateLimitKey))
+		}
+
+		r.Route("/users/{user}", func(r chi.Router) {
+			// Scope is checked before the user is resolved, so keys can't be used to discover users
+			forUser := func(scope canonical.APIKeyScope) chi.Router {
+				return r.With(h.requireScope(scope), h.targetUser, h.userLogger)
+			}
+
+			forUser(canonical.ScopeOrdersWrite).Post("/orders", h.addUsersOrder)
+			forUser(canonical.ScopeOrdersRead).Get("/orders", h.getUsersOrders)
+			forUser(canonical.ScopeBalanceRead).Get("/balance", h.getUsersBalance)
+			forUser(canonical.ScopeWithdrawalsWrite).Post("/balance/withdraw", h.addWithdrawal)
+		})
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(verifyToken(h.tokenAuth, h.tokenFinders()...))
+		r.Use(jwtauth.Authenticator)
+		r.Use(h.validateAccessToken)
+		r.Use(h.userLogger)
+		// Support can look users up, changes are made by admins only
+		r.Use(h.requireRole(canonical.RoleSupport, canonical.RoleAdmin))
+
+		r.Get("/users", h.searchUsers)
+
+		r.Route("/users/{user}", func(r chi.Router) {
+			r.Use(h.targetUser)
+
+			r.Get("/", h.getUser)
+			r.Get("/orders", h.getUsersOrders)
+			r.Get("/balance", h.getUsersBalance)
+			r.Get("/withdrawals", h.getUsersWithdrawals)
+			r.Get("/adjustments", h.getAdjustments)
+
+			r.Group(func(r chi.Router) {
+				r.Use(h.requireRole(canonical.RoleAdmin))
+				r.Use(middleware.AllowContentType("application/json"))
+
+				r.Post("/adjustments", h.addAdjustment)
+				r.Put("/role", h.setUserRole)
+			})
+		})
+	})
+
 	return r
 }
@@ -4,12 +4,18 @@ import (
 	"net/http"
 
 	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
 	"github.com/vstdy/gophermart/service/gophermart/v1"
 )
 
 // NewServer returns server.
-func NewServer(svc *gophermart.Service, config common.Config) *http.Server {
-	router := NewRouter(svc, config)
+func NewServer(
+	svc *gophermart.Service,
+	tokenAuth *tokenauth.TokenAuth,
+	config common.Config,
+	readiness *Readiness,
+) *http.Server {
+	router := NewRouter(svc, tokenAuth, config, readiness)
 
 	return &http.Server{Addr: config.RunAddress, Handler: router}
 }
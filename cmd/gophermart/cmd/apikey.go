@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/service/gophermart"
+)
+
+// newAPIKeyCmd creates a new apikey cmd.
+func newAPIKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage merchant API keys",
+	}
+
+	cmd.AddCommand(newAPIKeyCreateCmd())
+	cmd.AddCommand(newAPIKeyListCmd())
+	cmd.AddCommand(newAPIKeyRevokeCmd())
+
+	return cmd
+}
+
+// newAPIKeyCreateCmd creates a new apikey create cmd.
+func newAPIKeyCreateCmd() *cobra.Command {
+	const (
+		flagScopes = "scopes"
+		flagTTL    = "ttl"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Issue a new merchant API key (the key is shown once)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawScopes, err := cmd.Flags().GetStringSlice(flagScopes)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagScopes, err)
+			}
+			ttl, err := cmd.Flags().GetDuration(flagTTL)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagTTL, err)
+			}
+
+			scopes := make([]model.APIKeyScope, 0, len(rawScopes))
+			for _, scope := range rawScopes {
+				scopes = append(scopes, model.APIKeyScope(scope))
+			}
+
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				obj, err := svc.CreateAPIKey(ctx, args[0], scopes, ttl)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "API key %s (%s) created: %s\n", obj.ID, obj.Name, obj.Key)
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringSlice(flagScopes, nil, "Scopes [orders:read,orders:write,balance:read,withdrawals:write]")
+	cmd.Flags().Duration(flagTTL, 0, "Key lifetime (never expires if zero)")
+
+	return cmd
+}
+
+// newAPIKeyListCmd creates a new apikey list cmd.
+func newAPIKeyListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List merchant API keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				objs, err := svc.GetAPIKeys(ctx)
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tREVOKED")
+				for _, obj := range objs {
+					scopes := make([]string, 0, len(obj.Scopes))
+					for _, scope := range obj.Scopes {
+						scopes = append(scopes, string(scope))
+					}
+
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+						obj.ID, obj.Name, obj.Prefix, strings.Join(scopes, ","),
+						formatTime(obj.ExpiresAt), formatTime(obj.LastUsedAt), formatTime(obj.RevokedAt),
+					)
+				}
+
+				return w.Flush()
+			})
+		},
+	}
+
+	return cmd
+}
+
+// newAPIKeyRevokeCmd creates a new apikey revoke cmd.
+func newAPIKeyRevokeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke merchant API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("parsing id: %w", err)
+			}
+
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				if err := svc.RevokeAPIKey(ctx, id); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "API key %s revoked\n", id)
+
+				return nil
+			})
+		},
+	}
+
+	return cmd
+}
+
+// runWithService builds the service, runs fn with it within request timeout and closes the service.
+func runWithService(cmd *cobra.Command, fn func(ctx context.Context, svc gophermart.Service) error) error {
+	config := common.GetConfigFromCmdCtx(cmd)
+
+	ctx, ctxCancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer ctxCancel()
+
+	svc, err := config.BuildCommandService(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := svc.Close(); err != nil {
+			log.Error().Err(err).Msg("Shutting down the app")
+		}
+	}()
+
+	return fn(ctx, svc)
+}
+
+// formatTime formats time for CLI output, zero time is shown as dash.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/vstdy/gophermart/service/gophermart"
+)
+
+// newAuditCmd creates a new audit cmd.
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Manage balance operations audit log",
+	}
+
+	cmd.AddCommand(newAuditVerifyCmd())
+
+	return cmd
+}
+
+// newAuditVerifyCmd creates a new audit verify cmd.
+func newAuditVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify audit log hash chain and report the first broken link",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				result, err := svc.VerifyAuditLog(ctx)
+				if err != nil {
+					return err
+				}
+
+				if !result.Intact() {
+					return fmt.Errorf("audit log chain is broken at entry %d: %s (%d entries verified before)",
+						result.BrokenSeq, result.Reason, result.Checked)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Audit log is intact: %d entries verified\n", result.Checked)
+
+				return nil
+			})
+		},
+	}
+
+	return cmd
+}
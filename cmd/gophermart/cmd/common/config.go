@@ -3,10 +3,26 @@ package common
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/passhash"
+	"github.com/vstdy/gophermart/pkg/ratelimit"
+	"github.com/vstdy/gophermart/pkg/tokenauth"
+	"github.com/vstdy/gophermart/pkg/tracing"
 	"github.com/vstdy/gophermart/provider/accrual/http"
+	"github.com/vstdy/gophermart/provider/events"
+	fileevents "github.com/vstdy/gophermart/provider/events/file"
+	logevents "github.com/vstdy/gophermart/provider/events/log"
+	natsevents "github.com/vstdy/gophermart/provider/events/nats"
+	"github.com/vstdy/gophermart/provider/notifier"
+	filenotifier "github.com/vstdy/gophermart/provider/notifier/file"
+	lognotifier "github.com/vstdy/gophermart/provider/notifier/log"
+	smtpnotifier "github.com/vstdy/gophermart/provider/notifier/smtp"
+	webhook "github.com/vstdy/gophermart/provider/webhook/http"
 	"github.com/vstdy/gophermart/service/gophermart/v1"
 	"github.com/vstdy/gophermart/storage"
 	"github.com/vstdy/gophermart/storage/psql"
@@ -14,36 +30,112 @@ import (
 
 // Config combines sub-configs for all services, storages and providers.
 type Config struct {
-	Timeout     time.Duration     `mapstructure:"timeout"`
-	RunAddress  string            `mapstructure:"run_address"`
-	SecretKey   string            `mapstructure:"secret_key"`
-	StorageType string            `mapstructure:"storage_type"`
-	Provider    accrual.Config    `mapstructure:"provider,squash"`
-	Service     gophermart.Config `mapstructure:"service,squash"`
-	PSQLStorage psql.Config       `mapstructure:"psql_storage,squash"`
+	Timeout            time.Duration       `mapstructure:"timeout"`
+	ShutdownDrainDelay time.Duration       `mapstructure:"shutdown_drain_delay"`
+	RunAddress         string              `mapstructure:"run_address"`
+	TrustedProxies     []string            `mapstructure:"trusted_proxies"`
+	SecretKey          string              `mapstructure:"secret_key"`
+	AccessTokenTTL     time.Duration       `mapstructure:"access_token_ttl"`
+	ChallengeTTL       time.Duration       `mapstructure:"two_factor_challenge_ttl"`
+	AuthCookieEnabled  bool                `mapstructure:"auth_cookie_enabled"`
+	AuthCookieSecure   bool                `mapstructure:"auth_cookie_secure"`
+	AuthBearerEnabled  bool                `mapstructure:"auth_bearer_enabled"`
+	StorageType        string              `mapstructure:"storage_type"`
+	NotifierType       string              `mapstructure:"notifier_type"`
+	EventPublisherType string              `mapstructure:"event_publisher_type"`
+	Provider           accrual.Config      `mapstructure:"provider,squash"`
+	FileNotifier       filenotifier.Config `mapstructure:"file_notifier,squash"`
+	SMTPNotifier       smtpnotifier.Config `mapstructure:"smtp_notifier,squash"`
+	WebhookSender      webhook.Config      `mapstructure:"webhook_sender,squash"`
+	FileEvents         fileevents.Config   `mapstructure:"file_events,squash"`
+	NATSEvents         natsevents.Config   `mapstructure:"nats_events,squash"`
+	Service            gophermart.Config   `mapstructure:"service,squash"`
+	PSQLStorage        psql.Config         `mapstructure:"psql_storage,squash"`
+	RateLimit          ratelimit.Config    `mapstructure:"rate_limit,squash"`
+	Metrics            metrics.Config      `mapstructure:"metrics,squash"`
+	Tracing            tracing.Config      `mapstructure:"tracing,squash"`
+	TokenAuth          tokenauth.Config    `mapstructure:"token_auth,squash"`
+	PasswordHash       passhash.Config     `mapstructure:"password_hash,squash"`
 }
 
 const (
-	psqlStorage = "psql"
+	psqlStorage  = "psql"
+	logNotifier  = "log"
+	fileNotifier = "file"
+	smtpNotifier = "smtp"
+	logEvents    = "log"
+	fileEvents   = "file"
+	natsEvents   = "nats"
 )
 
 // BuildDefaultConfig builds a Config with default values.
 func BuildDefaultConfig() Config {
 	return Config{
-		Timeout:     5 * time.Second,
-		RunAddress:  "0.0.0.0:8080",
-		SecretKey:   "secret_key",
-		StorageType: psqlStorage,
-		Provider:    accrual.NewDefaultConfig(),
-		Service:     gophermart.NewDefaultConfig(),
-		PSQLStorage: psql.NewDefaultConfig(),
+		Timeout:            5 * time.Second,
+		ShutdownDrainDelay: 5 * time.Second,
+		RunAddress:         "0.0.0.0:8080",
+		SecretKey:          "secret_key",
+		AccessTokenTTL:     15 * time.Minute,
+		ChallengeTTL:       5 * time.Minute,
+		AuthCookieEnabled:  true,
+		AuthBearerEnabled:  true,
+		StorageType:        psqlStorage,
+		NotifierType:       logNotifier,
+		EventPublisherType: logEvents,
+		Provider:           accrual.NewDefaultConfig(),
+		FileNotifier:       filenotifier.NewDefaultConfig(),
+		SMTPNotifier:       smtpnotifier.NewDefaultConfig(),
+		WebhookSender:      webhook.NewDefaultConfig(),
+		FileEvents:         fileevents.NewDefaultConfig(),
+		NATSEvents:         natsevents.NewDefaultConfig(),
+		Service:            gophermart.NewDefaultConfig(),
+		PSQLStorage:        psql.NewDefaultConfig(),
+		RateLimit:          ratelimit.NewDefaultConfig(),
+		Metrics:            metrics.NewDefaultConfig(),
+		Tracing:            tracing.NewDefaultConfig(),
+		TokenAuth:          tokenauth.NewDefaultConfig(),
+		PasswordHash:       passhash.NewDefaultConfig(),
 	}
 }
 
+// TrustedProxyNets parses trusted proxies IP addresses and CIDR ranges.
+func (config Config) TrustedProxyNets() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(config.TrustedProxies))
+	for _, proxy := range config.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
 // BuildPsqlStorage builds psql.Storage dependency.
 func (config Config) BuildPsqlStorage() (*psql.Storage, error) {
+	hasher, err := passhash.NewHasher(config.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("building password hasher: %w", err)
+	}
+
 	st, err := psql.New(
 		psql.WithConfig(config.PSQLStorage),
+		psql.WithPasswordHasher(hasher),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("building psql storage: %w", err)
@@ -52,8 +144,81 @@ func (config Config) BuildPsqlStorage() (*psql.Storage, error) {
 	return st, nil
 }
 
+// BuildTokenAuth builds tokenauth.TokenAuth dependency.
+func (config Config) BuildTokenAuth() (*tokenauth.TokenAuth, error) {
+	tokenAuth, err := tokenauth.New(config.TokenAuth, config.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("building token auth: %w", err)
+	}
+
+	return tokenAuth, nil
+}
+
+// BuildNotifier builds notifier.Notifier dependency.
+func (config Config) BuildNotifier() (notifier.Notifier, error) {
+	switch config.NotifierType {
+	case logNotifier:
+		return lognotifier.NewNotifier(), nil
+	case fileNotifier:
+		n, err := filenotifier.NewNotifier(
+			filenotifier.WithConfig(config.FileNotifier),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("building file notifier: %w", err)
+		}
+		return n, nil
+	case smtpNotifier:
+		n, err := smtpnotifier.NewNotifier(
+			smtpnotifier.WithConfig(config.SMTPNotifier),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("building SMTP notifier: %w", err)
+		}
+		return n, nil
+	default:
+		return nil, pkg.ErrUnsupportedNotifierType
+	}
+}
+
+// BuildEventPublisher builds events.Publisher dependency.
+func (config Config) BuildEventPublisher() (events.Publisher, error) {
+	switch config.EventPublisherType {
+	case logEvents:
+		return logevents.NewPublisher(), nil
+	case fileEvents:
+		p, err := fileevents.NewPublisher(
+			fileevents.WithConfig(config.FileEvents),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("building file event publisher: %w", err)
+		}
+		return p, nil
+	case natsEvents:
+		p, err := natsevents.NewPublisher(
+			natsevents.WithConfig(config.NATSEvents),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("building NATS event publisher: %w", err)
+		}
+		return p, nil
+	default:
+		return nil, pkg.ErrUnsupportedPublisherType
+	}
+}
+
 // BuildService builds gophermart.Service dependency.
 func (config Config) BuildService(ctx context.Context) (*gophermart.Service, error) {
+	return config.buildService(ctx)
+}
+
+// BuildCommandService builds gophermart.Service dependency for one-shot CLI commands.
+// Background workers aren't started, so the service can be closed as soon as the command is done.
+func (config Config) BuildCommandService(ctx context.Context) (*gophermart.Service, error) {
+	return config.buildService(ctx, gophermart.WithoutBackgroundWorkers())
+}
+
+// buildService builds gophermart.Service dependency with extra options.
+func (config Config) buildService(ctx context.Context, opts ...gophermart.ServiceOption) (*gophermart.Service, error) {
 	var st storage.Storage
 	var err error
 
@@ -65,6 +230,23 @@ func (config Config) BuildService(ctx context.Context) (*gophermart.Service, err
 		return nil, fmt.Errorf("building provider: %w", err)
 	}
 
+	ntf, err := config.BuildNotifier()
+	if err != nil {
+		return nil, fmt.Errorf("building notifier: %w", err)
+	}
+
+	sender, err := webhook.NewSender(
+		webhook.WithConfig(config.WebhookSender),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building webhook sender: %w", err)
+	}
+
+	publisher, err := config.BuildEventPublisher()
+	if err != nil {
+		return nil, fmt.Errorf("building event publisher: %w", err)
+	}
+
 	switch config.StorageType {
 	case psqlStorage:
 		st, err = config.BuildPsqlStorage()
@@ -75,12 +257,16 @@ func (config Config) BuildService(ctx context.Context) (*gophermart.Service, err
 		return nil, fmt.Errorf("building storage: %w", err)
 	}
 
-	svc, err := gophermart.New(
-		ctx,
+	opts = append([]gophermart.ServiceOption{
 		gophermart.WithConfig(config.Service),
 		gophermart.WithProvider(prv),
+		gophermart.WithNotifier(ntf),
+		gophermart.WithWebhookSender(sender),
+		gophermart.WithEventPublisher(publisher),
 		gophermart.WithStorage(st),
-	)
+	}, opts...)
+
+	svc, err := gophermart.New(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("building service: %w", err)
 	}
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/lestrrat-go/jwx/jwa"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -17,6 +18,8 @@ import (
 	"github.com/vstdy/gophermart/api"
 	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
 	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/tracing"
 )
 
 const (
@@ -30,8 +33,199 @@ const (
 	envSecretKey           = "secret_key"
 	envUpdaterTimeout      = "updater_timeout"
 	envStatusCheckInterval = "status_check_interval"
+	envRateLimitEnabled    = "rate_limit_enabled"
+	envRateLimitPubRate    = "rate_limit_public_rate"
+	envRateLimitPubBurst   = "rate_limit_public_burst"
+	envRateLimitProtRate   = "rate_limit_protected_rate"
+	envRateLimitProtBurst  = "rate_limit_protected_burst"
+	envRateLimitIdleTTL    = "rate_limit_idle_ttl"
+	envMetricsEnabled      = "metrics_enabled"
+	envMetricsAddress      = "metrics_address"
+	envShutdownDrainDelay  = "shutdown_drain_delay"
+	envTrustedProxies      = "trusted_proxies"
+	envReadinessAccrual    = "readiness_check_accrual"
+	envTracingExporter     = "tracing_exporter"
+	envTracingEndpoint     = "tracing_otlp_endpoint"
+	envTracingInsecure     = "tracing_otlp_insecure"
+	envTracingSampleRatio  = "tracing_sample_ratio"
+	envAccessTokenTTL      = "access_token_ttl"
+	envRefreshTokenTTL     = "refresh_token_ttl"
+	envChallengeTTL        = "two_factor_challenge_ttl"
+	envTOTPIssuer          = "totp_issuer"
+	envTOTPSkew            = "totp_skew"
+	envTOTPRecoveryCodes   = "totp_recovery_codes"
+	envTOTPEncryptionKey   = "totp_encryption_key"
+	envAuthCookieEnabled   = "auth_cookie_enabled"
+	envAuthCookieSecure    = "auth_cookie_secure"
+	envAuthBearerEnabled   = "auth_bearer_enabled"
+	envJWTAlgorithm        = "jwt_algorithm"
+	envJWTSigningKey       = "jwt_signing_key"
+	envJWTSigningKeyFile   = "jwt_signing_key_file"
+	envJWTVerifyKeys       = "jwt_verification_keys"
+	envJWTVerifyKeyFiles   = "jwt_verification_key_files"
+	envLoginMinLength      = "login_min_length"
+	envLoginMaxLength      = "login_max_length"
+	envLoginPattern        = "login_pattern"
+	envLoginEmail          = "login_email"
+	envThrottleEnabled     = "login_throttle_enabled"
+	envThrottleWindow      = "login_throttle_window"
+	envThrottleDelayThresh = "login_throttle_delay_threshold"
+	envThrottleDelayBase   = "login_throttle_delay_base"
+	envThrottleDelayMax    = "login_throttle_delay_max"
+	envThrottleLockThresh  = "login_throttle_lockout_threshold"
+	envThrottleIPLockThr   = "login_throttle_ip_lockout_threshold"
+	envThrottleLockDur     = "login_throttle_lockout_duration"
+	envPasswordResetTTL    = "password_reset_ttl"
+	envEmailVerifyTTL      = "email_verification_ttl"
+	envNotifierType        = "notifier_type"
+	envNotifierFilePath    = "notifier_file_path"
+	envPasswordHashAlg     = "password_hash_algorithm"
+	envPasswordBcryptCost  = "password_bcrypt_cost"
+	envPasswordArgonTime   = "password_argon2_time"
+	envPasswordArgonMemory = "password_argon2_memory"
+	envPasswordArgonThread = "password_argon2_threads"
+	envPasswordMinLength   = "password_min_length"
+	envPasswordReqUpper    = "password_require_upper"
+	envPasswordReqLower    = "password_require_lower"
+	envPasswordReqDigit    = "password_require_digit"
+	envPasswordReqSymbol   = "password_require_symbol"
+	envPasswordRejCommon   = "password_reject_common"
+	envWebhookReqTimeout   = "webhook_request_timeout"
+	envWebhookInterval     = "webhook_dispatch_interval"
+	envWebhookTimeout      = "webhook_dispatch_timeout"
+	envWebhookBatchSize    = "webhook_batch_size"
+	envWebhookMaxAttempts  = "webhook_max_attempts"
+	envWebhookRetryBase    = "webhook_retry_base"
+	envWebhookRetryMax     = "webhook_retry_max"
+	envEventPublisherType  = "event_publisher_type"
+	envEventsFilePath      = "events_file_path"
+	envNATSURL             = "nats_url"
+	envNATSSubjectPrefix   = "nats_subject_prefix"
+	envNATSTimeout         = "nats_timeout"
+	envNATSJetStream       = "nats_jetstream"
+	envEventRelayInterval  = "event_relay_interval"
+	envEventRelayTimeout   = "event_relay_timeout"
+	envEventRelayBatchSize = "event_relay_batch_size"
+	envEventRelayAttempts  = "event_relay_max_attempts"
+	envSMTPHost            = "smtp_host"
+	envSMTPPort            = "smtp_port"
+	envSMTPUsername        = "smtp_username"
+	envSMTPPassword        = "smtp_password"
+	envSMTPFrom            = "smtp_from"
+	envSMTPSecurity        = "smtp_security"
+	envSMTPTimeout         = "smtp_timeout"
+	envEmailInterval       = "email_dispatch_interval"
+	envEmailTimeout        = "email_dispatch_timeout"
+	envEmailBatchSize      = "email_batch_size"
+	envEmailMaxAttempts    = "email_max_attempts"
+	envEmailRetryBase      = "email_retry_base"
+	envEmailRetryMax       = "email_retry_max"
+	envInboxInterval       = "inbox_interval"
+	envInboxTimeout        = "inbox_timeout"
+	envInboxBatchSize      = "inbox_batch_size"
+	envInboxRetention      = "inbox_retention"
+	envInboxCleanupInt     = "inbox_cleanup_interval"
 )
 
+// envKeys defines config keys which can be set with ENV variables only.
+var envKeys = []string{
+	envSecretKey,
+	envUpdaterTimeout,
+	envStatusCheckInterval,
+	envRateLimitEnabled,
+	envRateLimitPubRate,
+	envRateLimitPubBurst,
+	envRateLimitProtRate,
+	envRateLimitProtBurst,
+	envRateLimitIdleTTL,
+	envMetricsEnabled,
+	envMetricsAddress,
+	envShutdownDrainDelay,
+	envTrustedProxies,
+	envReadinessAccrual,
+	envTracingExporter,
+	envTracingEndpoint,
+	envTracingInsecure,
+	envTracingSampleRatio,
+	envAccessTokenTTL,
+	envRefreshTokenTTL,
+	envChallengeTTL,
+	envTOTPIssuer,
+	envTOTPSkew,
+	envTOTPRecoveryCodes,
+	envTOTPEncryptionKey,
+	envAuthCookieEnabled,
+	envAuthCookieSecure,
+	envAuthBearerEnabled,
+	envJWTAlgorithm,
+	envJWTSigningKey,
+	envJWTSigningKeyFile,
+	envJWTVerifyKeys,
+	envJWTVerifyKeyFiles,
+	envLoginMinLength,
+	envLoginMaxLength,
+	envLoginPattern,
+	envLoginEmail,
+	envThrottleEnabled,
+	envThrottleWindow,
+	envThrottleDelayThresh,
+	envThrottleDelayBase,
+	envThrottleDelayMax,
+	envThrottleLockThresh,
+	envThrottleIPLockThr,
+	envThrottleLockDur,
+	envPasswordResetTTL,
+	envEmailVerifyTTL,
+	envNotifierType,
+	envNotifierFilePath,
+	envPasswordHashAlg,
+	envPasswordBcryptCost,
+	envPasswordArgonTime,
+	envPasswordArgonMemory,
+	envPasswordArgonThread,
+	envPasswordMinLength,
+	envPasswordReqUpper,
+	envPasswordReqLower,
+	envPasswordReqDigit,
+	envPasswordReqSymbol,
+	envPasswordRejCommon,
+	envWebhookReqTimeout,
+	envWebhookInterval,
+	envWebhookTimeout,
+	envWebhookBatchSize,
+	envWebhookMaxAttempts,
+	envWebhookRetryBase,
+	envWebhookRetryMax,
+	envEventPublisherType,
+	envEventsFilePath,
+	envNATSURL,
+	envNATSSubjectPrefix,
+	envNATSTimeout,
+	envNATSJetStream,
+	envEventRelayInterval,
+	envEventRelayTimeout,
+	envEventRelayBatchSize,
+	envEventRelayAttempts,
+	envSMTPHost,
+	envSMTPPort,
+	envSMTPUsername,
+	envSMTPPassword,
+	envSMTPFrom,
+	envSMTPSecurity,
+	envSMTPTimeout,
+	envEmailInterval,
+	envEmailTimeout,
+	envEmailBatchSize,
+	envEmailMaxAttempts,
+	envEmailRetryBase,
+	envEmailRetryMax,
+	envInboxInterval,
+	envInboxTimeout,
+	envInboxBatchSize,
+	envInboxRetention,
+	envInboxCleanupInt,
+}
+
 // Execute prepares cobra.Command context and executes root cmd.
 func Execute() error {
 	return newRootCmd().ExecuteContext(common.NewBaseCmdCtx())
@@ -54,6 +248,28 @@ func newRootCmd() *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			config := common.GetConfigFromCmdCtx(cmd)
+			if err := config.RateLimit.Validate(); err != nil {
+				return fmt.Errorf("app initialization: rate limit config validation: %w", err)
+			}
+
+			shutdownTracing, err := tracing.Setup(context.Background(), config.Tracing)
+			if err != nil {
+				return fmt.Errorf("app initialization: tracing setup: %w", err)
+			}
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				if err := shutdownTracing(ctx); err != nil {
+					log.Error().Err(err).Msg("shutting down tracing")
+				}
+			}()
+
+			tokenAuth, err := config.BuildTokenAuth()
+			if err != nil {
+				return fmt.Errorf("app initialization: %w", err)
+			}
+
 			svcCtx, svcCancel := context.WithCancel(context.Background())
 			defer svcCancel()
 
@@ -62,7 +278,8 @@ func newRootCmd() *cobra.Command {
 				return fmt.Errorf("app initialization: service building: %w", err)
 			}
 
-			srv := api.NewServer(svc, config)
+			readiness := api.NewReadiness()
+			srv := api.NewServer(svc, tokenAuth, config, readiness)
 
 			go func() {
 				if err = srv.ListenAndServe(); err != http.ErrServerClosed {
@@ -70,10 +287,26 @@ func newRootCmd() *cobra.Command {
 				}
 			}()
 
+			var metricsSrv *http.Server
+			if config.Metrics.Enabled && config.Metrics.Address != "" {
+				metricsSrv = metrics.NewServer(config.Metrics)
+
+				go func() {
+					if err := metricsSrv.ListenAndServe(); err != http.ErrServerClosed {
+						log.Error().Err(err).Msg("Metrics server ListenAndServe")
+					}
+				}()
+			}
+
 			stop := make(chan os.Signal, 1)
 			signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 			<-stop
 
+			// Fail readiness probe first to let the load balancer drain the traffic
+			readiness.SetShuttingDown()
+			log.Info().Msgf("draining traffic for %s", config.ShutdownDrainDelay)
+			time.Sleep(config.ShutdownDrainDelay)
+
 			svcCancel()
 
 			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -83,6 +316,12 @@ func newRootCmd() *cobra.Command {
 				return fmt.Errorf("shutting shutdown server: %w", err)
 			}
 
+			if metricsSrv != nil {
+				if err = metricsSrv.Shutdown(shutdownCtx); err != nil {
+					log.Error().Err(err).Msg("shutting down metrics server")
+				}
+			}
+
 			if err = svc.Close(); err != nil {
 				log.Error().Err(err).Msg("shutting down service")
 			}
@@ -103,6 +342,10 @@ func newRootCmd() *cobra.Command {
 	cmd.Flags().StringP(flagAccrualSysAddress, "r", config.Provider.AccrualSysAddress, "Accrual system address")
 
 	cmd.AddCommand(newMigrateCmd())
+	cmd.AddCommand(newUserCmd())
+	cmd.AddCommand(newAPIKeyCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newWebhookCmd())
 
 	return cmd
 }
@@ -123,6 +366,8 @@ func setupLogger(cmd *cobra.Command) error {
 		TimeFormat: time.RFC3339,
 	}
 	log.Logger = log.Output(logWriter).Level(logLevel)
+	// Contexts without request scoped logger fall back to the global one
+	zerolog.DefaultContextLogger = &log.Logger
 
 	return nil
 }
@@ -133,14 +378,10 @@ func setupConfig(cmd *cobra.Command) error {
 		return fmt.Errorf("flags binding: %w", err)
 	}
 
-	if err := viper.BindEnv(envSecretKey); err != nil {
-		return fmt.Errorf("%s env binding: %w", envSecretKey, err)
-	}
-	if err := viper.BindEnv(envUpdaterTimeout); err != nil {
-		return fmt.Errorf("%s env binding: %w", envUpdaterTimeout, err)
-	}
-	if err := viper.BindEnv(envStatusCheckInterval); err != nil {
-		return fmt.Errorf("%s env binding: %w", envStatusCheckInterval, err)
+	for _, key := range envKeys {
+		if err := viper.BindEnv(key); err != nil {
+			return fmt.Errorf("%s env binding: %w", key, err)
+		}
 	}
 
 	configPath := viper.GetString(flagConfigPath)
@@ -162,9 +403,25 @@ func setupConfig(cmd *cobra.Command) error {
 
 	common.SetConfigToCmdCtx(cmd, config)
 
-	if config.SecretKey == "" {
+	if config.TokenAuth.Algorithm == jwa.HS256.String() && config.SecretKey == "" {
 		return fmt.Errorf("%s config: %w", envSecretKey, pkg.ErrNoValue)
 	}
 
+	if config.AccessTokenTTL < time.Second {
+		return fmt.Errorf("%s config: too short period", envAccessTokenTTL)
+	}
+
+	if config.ChallengeTTL < 30*time.Second {
+		return fmt.Errorf("%s config: too short period", envChallengeTTL)
+	}
+
+	if _, err := config.TrustedProxyNets(); err != nil {
+		return fmt.Errorf("%s config: %w", envTrustedProxies, err)
+	}
+
+	if !config.AuthCookieEnabled && !config.AuthBearerEnabled {
+		return fmt.Errorf("%s, %s config: at least one auth mode must be enabled", envAuthCookieEnabled, envAuthBearerEnabled)
+	}
+
 	return nil
 }
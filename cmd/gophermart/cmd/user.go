@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/vstdy/gophermart/cmd/gophermart/cmd/common"
+	"github.com/vstdy/gophermart/model"
+)
+
+// newUserCmd creates a new user cmd.
+func newUserCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+	}
+
+	cmd.AddCommand(newUserUnlockCmd())
+	cmd.AddCommand(newUserPromoteCmd())
+
+	return cmd
+}
+
+// newUserUnlockCmd creates a new user unlock cmd.
+func newUserUnlockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unlock <login>",
+		Short: "Reset failed login attempts and lockout of the user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := common.GetConfigFromCmdCtx(cmd)
+
+			ctx, ctxCancel := context.WithTimeout(context.Background(), config.Timeout)
+			defer ctxCancel()
+
+			svc, err := config.BuildCommandService(ctx)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err = svc.Close(); err != nil {
+					log.Error().Err(err).Msg("Shutting down the app")
+				}
+			}()
+
+			if err = svc.UnlockLogin(ctx, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "User %s unlocked\n", args[0])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// newUserPromoteCmd creates a new user promote cmd.
+func newUserPromoteCmd() *cobra.Command {
+	const flagRole = "role"
+
+	cmd := &cobra.Command{
+		Use:   "promote <login>",
+		Short: "Set role of the user (e.g. bootstrap the first admin)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := common.GetConfigFromCmdCtx(cmd)
+
+			role, err := cmd.Flags().GetString(flagRole)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagRole, err)
+			}
+
+			ctx, ctxCancel := context.WithTimeout(context.Background(), config.Timeout)
+			defer ctxCancel()
+
+			svc, err := config.BuildCommandService(ctx)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err = svc.Close(); err != nil {
+					log.Error().Err(err).Msg("Shutting down the app")
+				}
+			}()
+
+			user, err := svc.SetUserRoleByLogin(ctx, args[0], model.Role(role))
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "User %s (%s) role set to %s\n", user.Login, user.ID, user.Role)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagRole, string(model.RoleAdmin), "Role [user,support,admin]")
+
+	return cmd
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/service/gophermart"
+)
+
+// newWebhookCmd creates a new webhook cmd.
+func newWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Manage webhook deliveries",
+	}
+
+	cmd.AddCommand(newWebhookListCmd())
+	cmd.AddCommand(newWebhookReplayCmd())
+
+	return cmd
+}
+
+// newWebhookListCmd creates a new webhook list cmd.
+func newWebhookListCmd() *cobra.Command {
+	const (
+		flagStatus = "status"
+		flagLimit  = "limit"
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List webhook deliveries, latest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := cmd.Flags().GetString(flagStatus)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagStatus, err)
+			}
+			limit, err := cmd.Flags().GetInt(flagLimit)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagLimit, err)
+			}
+
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				objs, err := svc.GetWebhookDeliveries(ctx, model.WebhookDeliveryStatus(status), limit)
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tWEBHOOK\tEVENT\tSTATUS\tATTEMPTS\tNEXT ATTEMPT\tLAST STATUS\tLAST ERROR")
+				for _, obj := range objs {
+					nextAttempt := "-"
+					if obj.Status == model.WebhookDeliveryPending {
+						nextAttempt = formatTime(obj.NextAttemptAt)
+					}
+
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%d\t%s\n",
+						obj.ID, obj.Webhook, obj.Event.Type, obj.Status, obj.Attempts,
+						nextAttempt, obj.LastStatusCode, obj.LastError,
+					)
+				}
+
+				return w.Flush()
+			})
+		},
+	}
+
+	cmd.Flags().String(flagStatus, "", "Delivery status [pending,delivered,dead] (any if empty)")
+	cmd.Flags().Int(flagLimit, 0, "Maximum number of deliveries listed (50 if zero)")
+
+	return cmd
+}
+
+// newWebhookReplayCmd creates a new webhook replay cmd.
+func newWebhookReplayCmd() *cobra.Command {
+	const flagDead = "dead"
+
+	cmd := &cobra.Command{
+		Use:   "replay [<id>...]",
+		Short: "Schedule webhook deliveries for immediate delivery with attempts counter reset",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dead, err := cmd.Flags().GetBool(flagDead)
+			if err != nil {
+				return fmt.Errorf("%s flag reading: %w", flagDead, err)
+			}
+			if dead == (len(args) > 0) {
+				return fmt.Errorf("either delivery IDs or --%s flag must be given", flagDead)
+			}
+
+			ids := make([]uuid.UUID, 0, len(args))
+			for _, arg := range args {
+				id, err := uuid.Parse(arg)
+				if err != nil {
+					return fmt.Errorf("parsing id %s: %w", arg, err)
+				}
+				ids = append(ids, id)
+			}
+
+			return runWithService(cmd, func(ctx context.Context, svc gophermart.Service) error {
+				count, err := svc.ReplayWebhookDeliveries(ctx, ids)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Webhook deliveries scheduled: %d\n", count)
+
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().Bool(flagDead, false, "Replay all dead deliveries")
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope defines merchant API key permission.
+type APIKeyScope string
+
+const (
+	ScopeOrdersRead       APIKeyScope = "orders:read"
+	ScopeOrdersWrite      APIKeyScope = "orders:write"
+	ScopeBalanceRead      APIKeyScope = "balance:read"
+	ScopeWithdrawalsWrite APIKeyScope = "withdrawals:write"
+)
+
+// Validate checks that scope is a known one.
+func (s APIKeyScope) Validate() error {
+	switch s {
+	case ScopeOrdersRead, ScopeOrdersWrite, ScopeBalanceRead, ScopeWithdrawalsWrite:
+		return nil
+	default:
+		return fmt.Errorf("unknown scope: %s", s)
+	}
+}
+
+// APIKey keeps merchant API key data.
+type APIKey struct {
+	ID   uuid.UUID
+	Name string
+	// Key is only set on key creation
+	Key string
+	// Prefix is the non-secret beginning of the key to tell keys apart
+	Prefix     string
+	Scopes     []APIKeyScope
+	ExpiresAt  time.Time
+	RevokedAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+}
+
+// HasScope checks whether the key is granted given scope.
+func (k APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
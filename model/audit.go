@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// AuditAction defines audited balance operation.
+	AuditAction string
+
+	// ActorType defines kind of operation initiator.
+	ActorType string
+)
+
+const (
+	AuditActionAccrual    AuditAction = "accrual"
+	AuditActionWithdrawal AuditAction = "withdrawal"
+	AuditActionAdjustment AuditAction = "adjustment"
+
+	// ActorSystem is the app itself (e.g. background jobs).
+	ActorSystem ActorType = "system"
+	// ActorUser is a user authenticated with access token (including operators).
+	ActorUser ActorType = "user"
+	// ActorAPIKey is a merchant authenticated with API key.
+	ActorAPIKey ActorType = "api_key"
+)
+
+// Actor keeps operation initiator data.
+type Actor struct {
+	Type ActorType
+	ID   string
+}
+
+// AuditEntry keeps audit log entry data.
+// Each entry hash covers its data and the previous entry hash, so entries can't be altered unnoticed.
+type AuditEntry struct {
+	Seq       int64
+	Action    AuditAction
+	Actor     Actor
+	RequestID string
+	UserID    uuid.UUID
+	// Reference is an order number or a transaction ID
+	Reference     string
+	Amount        float32
+	BalanceBefore float32
+	BalanceAfter  float32
+	PrevHash      string
+	Hash          string
+	CreatedAt     time.Time
+}
+
+// AuditVerification keeps audit log hash chain verification result.
+type AuditVerification struct {
+	Checked int64
+	// BrokenSeq is the sequence number of the first entry failed verification
+	BrokenSeq int64
+	Reason    string
+}
+
+// Intact reports whether the chain is verified entirely.
+func (v AuditVerification) Intact() bool {
+	return v.Reason == ""
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationToken keeps email verification token data:
+// user email is set to Email once the token is used.
+type EmailVerificationToken struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Email  string
+	// Token is only set on token creation
+	Token     string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType defines domain event kind.
+type EventType string
+
+const (
+	EventTypeUserRegistered     EventType = "user.registered"
+	EventTypeOrderUploaded      EventType = "order.uploaded"
+	EventTypeOrderStatusChanged EventType = "order.status_changed"
+	EventTypeOrderProcessed     EventType = "order.processed"
+	EventTypePointsAccrued      EventType = "points.accrued"
+	EventTypePointsWithdrawn    EventType = "points.withdrawn"
+	EventTypePointsAdjusted     EventType = "points.adjusted"
+	EventTypeWithdrawalCreated  EventType = "withdrawal.created"
+)
+
+// Validate checks that event type is a known one.
+func (t EventType) Validate() error {
+	switch t {
+	case EventTypeUserRegistered, EventTypeOrderUploaded, EventTypeOrderStatusChanged, EventTypeOrderProcessed,
+		EventTypePointsAccrued, EventTypePointsWithdrawn, EventTypePointsAdjusted, EventTypeWithdrawalCreated:
+		return nil
+	default:
+		return fmt.Errorf("unknown event type: %s", t)
+	}
+}
+
+// Event keeps domain event data written to the outbox.
+type Event struct {
+	ID        uuid.UUID
+	Type      EventType
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Event payloads are delivered to event consumers as is.
+// Points events are emitted on balance changes made by orders, order.processed and withdrawal.created
+// events are the subset of them kept for webhook subscriptions.
+type (
+	// UserRegisteredPayload keeps user.registered event data.
+	UserRegisteredPayload struct {
+		UserID       uuid.UUID `json:"user_id"`
+		Login        string    `json:"login"`
+		RegisteredAt time.Time `json:"registered_at"`
+	}
+
+	// OrderUploadedPayload keeps order.uploaded event data.
+	OrderUploadedPayload struct {
+		Order      string    `json:"order"`
+		UserID     uuid.UUID `json:"user_id"`
+		Status     string    `json:"status"`
+		UploadedAt time.Time `json:"uploaded_at"`
+	}
+
+	// OrderStatusChangedPayload keeps order.status_changed event data.
+	OrderStatusChangedPayload struct {
+		Order          string    `json:"order"`
+		UserID         uuid.UUID `json:"user_id"`
+		PreviousStatus string    `json:"previous_status"`
+		Status         string    `json:"status"`
+		Accrual        float32   `json:"accrual"`
+	}
+
+	// OrderProcessedPayload keeps order.processed event data.
+	OrderProcessedPayload struct {
+		Order   string    `json:"order"`
+		UserID  uuid.UUID `json:"user_id"`
+		Status  string    `json:"status"`
+		Accrual float32   `json:"accrual"`
+	}
+
+	// PointsAccruedPayload keeps points.accrued event data.
+	PointsAccruedPayload struct {
+		Order   string    `json:"order"`
+		UserID  uuid.UUID `json:"user_id"`
+		Amount  float32   `json:"amount"`
+		Balance float32   `json:"balance"`
+	}
+
+	// PointsWithdrawnPayload keeps points.withdrawn event data.
+	PointsWithdrawnPayload struct {
+		Order   string    `json:"order"`
+		UserID  uuid.UUID `json:"user_id"`
+		Amount  float32   `json:"amount"`
+		Balance float32   `json:"balance"`
+	}
+
+	// PointsAdjustedPayload keeps points.adjusted event data, amount is negative for debits.
+	PointsAdjustedPayload struct {
+		AdjustmentID uuid.UUID `json:"adjustment_id"`
+		UserID       uuid.UUID `json:"user_id"`
+		Amount       float32   `json:"amount"`
+		Balance      float32   `json:"balance"`
+		Reason       string    `json:"reason"`
+	}
+
+	// WithdrawalCreatedPayload keeps withdrawal.created event data.
+	WithdrawalCreatedPayload struct {
+		Order       string    `json:"order"`
+		UserID      uuid.UUID `json:"user_id"`
+		Sum         float32   `json:"sum"`
+		ProcessedAt time.Time `json:"processed_at"`
+	}
+)
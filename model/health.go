@@ -0,0 +1,7 @@
+package model
+
+// HealthCheck keeps dependency health check result.
+type HealthCheck struct {
+	Name string
+	Err  error
+}
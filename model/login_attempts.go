@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginAttemptsKind defines what failed login attempts are tracked by.
+type LoginAttemptsKind string
+
+const (
+	LoginAttemptsByLogin LoginAttemptsKind = "login"
+	LoginAttemptsByIP    LoginAttemptsKind = "ip"
+)
+
+type (
+	// LoginAttemptsKey identifies failed login attempts counter.
+	LoginAttemptsKey struct {
+		Kind    LoginAttemptsKind
+		Subject string
+	}
+
+	// LoginAttempts keeps failed login attempts data.
+	LoginAttempts struct {
+		LoginAttemptsKey
+		Failures     int
+		LastFailedAt time.Time
+		LockedUntil  time.Time
+	}
+
+	// LoginAttemptsRule defines when further login attempts are locked.
+	// Attempts are delayed after DelayThreshold failures (zero disables delays) for DelayBase doubling
+	// with every further failure up to DelayMax, and locked out for LockoutDuration after LockoutThreshold failures.
+	// Failures are counted within Window since the last one.
+	LoginAttemptsRule struct {
+		Window           time.Duration
+		DelayThreshold   int
+		DelayBase        time.Duration
+		DelayMax         time.Duration
+		LockoutThreshold int
+		LockoutDuration  time.Duration
+	}
+
+	// ClientInfo keeps request client data.
+	ClientInfo struct {
+		IP        string
+		UserAgent string
+	}
+)
@@ -0,0 +1,8 @@
+package model
+
+// Message keeps notification message data.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
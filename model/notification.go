@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType defines user notification kind.
+type NotificationType string
+
+const (
+	NotificationAccrualProcessed NotificationType = "accrual_processed"
+	NotificationWithdrawalMade   NotificationType = "withdrawal_made"
+	NotificationOrderInvalid     NotificationType = "order_invalid"
+)
+
+// NotificationTypes lists all notification types.
+var NotificationTypes = []NotificationType{
+	NotificationAccrualProcessed,
+	NotificationWithdrawalMade,
+	NotificationOrderInvalid,
+}
+
+// Validate checks that notification type is a known one.
+func (t NotificationType) Validate() error {
+	switch t {
+	case NotificationAccrualProcessed, NotificationWithdrawalMade, NotificationOrderInvalid:
+		return nil
+	default:
+		return fmt.Errorf("unknown notification type: %s", t)
+	}
+}
+
+// NotificationSettings keeps user notification settings.
+type NotificationSettings struct {
+	Email         string
+	EmailVerified bool
+	// EmailEnabled keeps notification types user opted in to receive by email
+	EmailEnabled map[NotificationType]bool
+}
+
+// EmailStatus defines email notification sending state.
+type EmailStatus string
+
+const (
+	// EmailPending is an email waiting for (the next) sending attempt.
+	EmailPending EmailStatus = "pending"
+	// EmailSent is an email accepted by the mail server.
+	EmailSent EmailStatus = "sent"
+	// EmailFailed is an email failed all the sending attempts.
+	EmailFailed EmailStatus = "failed"
+)
+
+// Email keeps email notification data.
+type Email struct {
+	ID uuid.UUID
+	// EventID is the outbox event the notification is made of
+	EventID       uuid.UUID
+	UserID        uuid.UUID
+	Type          NotificationType
+	Message       Message
+	Status        EmailStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        time.Time
+	CreatedAt     time.Time
+}
+
+// Notification keeps in-app notification data.
+type Notification struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// EventID is the outbox event the notification is made of
+	EventID   uuid.UUID
+	Type      EventType
+	Message   string
+	Data      json.RawMessage
+	ReadAt    time.Time
+	CreatedAt time.Time
+}
+
+// NotificationList keeps a page of user notifications along with user notification counters.
+type NotificationList struct {
+	Notifications []Notification
+	Total         int
+	Unread        int
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken keeps password reset token data.
+type PasswordResetToken struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Token is only set on token creation
+	Token     string
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
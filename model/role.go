@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+)
+
+// Role defines user access level.
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleSupport Role = "support"
+	RoleAdmin   Role = "admin"
+)
+
+// Validate checks that role is a known one.
+func (r Role) Validate() error {
+	switch r {
+	case RoleUser, RoleSupport, RoleAdmin:
+		return nil
+	default:
+		return fmt.Errorf("unknown role: %s", r)
+	}
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session keeps refresh token session data.
+type Session struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// RefreshToken is only set on session creation and refresh
+	RefreshToken string
+	// UserRole is filled on session creation and refresh to be embedded into access token
+	UserRole Role
+	// IP and UserAgent are of the client the session was last used by
+	IP        string
+	UserAgent string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AccessToken keeps access token (JWT) data.
+type AccessToken struct {
+	ID        string
+	UserID    uuid.UUID
+	SessionID uuid.UUID
+	Role      Role
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// NewAccessToken creates a new AccessToken model for given session user.
+func NewAccessToken(session Session, ttl time.Duration) AccessToken {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return AccessToken{
+		ID:        uuid.NewString(),
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Role:      session.UserRole,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
@@ -7,14 +7,23 @@ import (
 )
 
 // Transaction keeps order data.
+// Manual balance adjustments have no order and are made by operator with a reason.
 type Transaction struct {
+	ID          uuid.UUID
 	UserID      uuid.UUID
 	Order       string
 	Accrual     float32
 	Withdrawal  float32
+	OperatorID  uuid.UUID
+	Reason      string
 	ProcessedAt time.Time
 }
 
+// IsAdjustment reports whether the transaction is a manual balance adjustment.
+func (t Transaction) IsAdjustment() bool {
+	return t.OperatorID != uuid.Nil
+}
+
 // NewTransaction creates a new Transaction model from Order model.
 func NewTransaction(obj Order) Transaction {
 	return Transaction{
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor keeps user TOTP two-factor authentication data.
+type TwoFactor struct {
+	UserID uuid.UUID
+	Secret string
+	// LastUsedStep is the time step of the last accepted code, codes of this and earlier steps are rejected
+	LastUsedStep int64
+	ConfirmedAt  time.Time
+	CreatedAt    time.Time
+}
+
+// Enabled reports whether enrolment is confirmed, so codes are required on login.
+func (obj TwoFactor) Enabled() bool {
+	return !obj.ConfirmedAt.IsZero()
+}
+
+// TwoFactorEnrollment keeps data to add TOTP secret to authenticator app.
+type TwoFactorEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// TwoFactorChallenge proves password authentication of user with 2FA enabled, each one completes login once.
+type TwoFactorChallenge struct {
+	ID        string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+}
+
+// NewTwoFactorChallenge creates a new TwoFactorChallenge model for given user.
+func NewTwoFactorChallenge(userID uuid.UUID, ttl time.Duration) TwoFactorChallenge {
+	return TwoFactorChallenge{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		ExpiresAt: time.Now().UTC().Truncate(time.Second).Add(ttl),
+	}
+}
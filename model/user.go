@@ -6,12 +6,36 @@ import (
 	"github.com/google/uuid"
 )
 
+// BirthdayLayout is a layout of user birthday date.
+const BirthdayLayout = "2006-01-02"
+
 // User keeps user data.
+// EmailVerifiedAt is zero if Email was set before email verification was introduced.
 type User struct {
-	ID        uuid.UUID
-	Login     string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt time.Time
+	ID              uuid.UUID
+	Login           string
+	Password        string
+	Role            Role
+	Email           string
+	EmailVerifiedAt time.Time
+	DisplayName     string
+	Phone           string
+	Birthday        time.Time
+	Language        string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       time.Time
+	// TwoFactorEnabled is only set on authentication
+	TwoFactorEnabled bool
+}
+
+// ProfileUpdate keeps user profile changes: nil fields are kept unchanged, empty ones are cleared.
+// Birthday is a date in BirthdayLayout format. Email is set with notification settings only,
+// so it is never changed with the profile (non-nil Email is rejected).
+type ProfileUpdate struct {
+	DisplayName *string
+	Email       *string
+	Phone       *string
+	Birthday    *string
+	Language    *string
 }
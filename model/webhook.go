@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryStatus defines webhook delivery state.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is a delivery waiting for (the next) attempt.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered is a delivery acknowledged by the receiver.
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead is a delivery failed all the attempts (dead letter), it can be replayed.
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery keeps event delivery to webhook subscription data.
+type WebhookDelivery struct {
+	ID uuid.UUID
+	// Webhook is the subscription name
+	Webhook        string
+	Event          Event
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// WebhookMessage keeps webhook request data.
+type WebhookMessage struct {
+	URL        string
+	Secret     string
+	DeliveryID uuid.UUID
+	Event      Event
+}
@@ -0,0 +1,40 @@
+// Package audit passes audit data (operation actor and request ID) through the request context.
+package audit
+
+import (
+	"context"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+)
+
+const (
+	ctxKeyActor     = pkg.ContextKey("audit_actor")
+	ctxKeyRequestID = pkg.ContextKey("audit_request_id")
+)
+
+// WithActor returns a copy of ctx carrying operation actor.
+func WithActor(ctx context.Context, actor model.Actor) context.Context {
+	return context.WithValue(ctx, ctxKeyActor, actor)
+}
+
+// ActorFromContext returns operation actor from ctx, the system is the actor if none is set.
+func ActorFromContext(ctx context.Context) model.Actor {
+	if actor, ok := ctx.Value(ctxKeyActor).(model.Actor); ok {
+		return actor
+	}
+
+	return model.Actor{Type: model.ActorSystem}
+}
+
+// WithRequestID returns a copy of ctx carrying request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID, requestID)
+}
+
+// RequestIDFromContext returns request ID from ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(ctxKeyRequestID).(string)
+
+	return requestID
+}
@@ -1,12 +1,43 @@
 package pkg
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
-	ErrUnsupportedStorageType = errors.New("unsupported storage type")
-	ErrInvalidInput           = errors.New("invalid input")
-	ErrAlreadyExists          = errors.New("object exists in the DB")
-	ErrWrongCredentials       = errors.New("wrong credentials")
-	ErrNoValue                = errors.New("value is missing")
-	ErrNonSufficientFunds     = errors.New("non-sufficient funds")
+	ErrUnsupportedStorageType   = errors.New("unsupported storage type")
+	ErrUnsupportedNotifierType  = errors.New("unsupported notifier type")
+	ErrUnsupportedPublisherType = errors.New("unsupported event publisher type")
+	ErrInvalidInput             = errors.New("invalid input")
+	ErrNotFound                 = errors.New("object not found")
+	ErrAlreadyExists            = errors.New("object exists in the DB")
+	ErrWrongCredentials         = errors.New("wrong credentials")
+	ErrNoValue                  = errors.New("value is missing")
+	ErrNonSufficientFunds       = errors.New("non-sufficient funds")
+	ErrInvalidToken             = errors.New("invalid or revoked token")
+	ErrTooManyAttempts          = errors.New("too many failed attempts")
+	ErrAccountLocked            = errors.New("account is temporarily locked")
+	ErrForbidden                = errors.New("action is forbidden")
 )
+
+// RetryAfterError wraps an error with time after which the action can be retried.
+type RetryAfterError struct {
+	Err     error
+	RetryAt time.Time
+}
+
+// NewRetryAfterError creates a new RetryAfterError.
+func NewRetryAfterError(err error, retryAt time.Time) error {
+	return RetryAfterError{Err: err, RetryAt: retryAt}
+}
+
+// Error implements error interface.
+func (e RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns wrapped error.
+func (e RetryAfterError) Unwrap() error {
+	return e.Err
+}
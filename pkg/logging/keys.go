@@ -9,4 +9,37 @@ const (
 
 	// IDKey defines logging key to track object ID.
 	IDKey = "id"
+
+	// RequestIDKey defines logging key to track HTTP request ID.
+	RequestIDKey = "request-id"
+
+	// TraceIDKey defines logging key to track trace ID.
+	TraceIDKey = "trace-id"
+
+	// UserIDKey defines logging key to track authenticated user ID.
+	UserIDKey = "user-id"
+
+	// RouteKey defines logging key to track matched HTTP route.
+	RouteKey = "route"
+
+	// MethodKey defines logging key to track HTTP request method.
+	MethodKey = "method"
+
+	// PathKey defines logging key to track HTTP request path.
+	PathKey = "path"
+
+	// RemoteIPKey defines logging key to track HTTP request client IP.
+	RemoteIPKey = "remote-ip"
+
+	// StatusKey defines logging key to track HTTP response status.
+	StatusKey = "status"
+
+	// BytesKey defines logging key to track HTTP response size.
+	BytesKey = "bytes"
+
+	// JobKey defines logging key to track background job.
+	JobKey = "job"
+
+	// APIKeyIDKey defines logging key to track authenticated merchant API key ID.
+	APIKeyIDKey = "api-key-id"
 )
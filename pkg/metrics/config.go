@@ -0,0 +1,14 @@
+package metrics
+
+// Config keeps metrics exposition params.
+type Config struct {
+	Enabled bool   `mapstructure:"metrics_enabled"`
+	Address string `mapstructure:"metrics_address"`
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Enabled: true,
+	}
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "gophermart"
+
+	// Path defines metrics endpoint path.
+	Path = "/metrics"
+)
+
+var (
+	// HTTPRequestsTotal counts handled HTTP requests.
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of handled HTTP requests.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration observes HTTP requests latency.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP requests latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// AccrualRequestsTotal counts accrual system calls.
+	AccrualRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "requests_total",
+		Help:      "Number of accrual system calls.",
+	}, []string{"code"})
+
+	// AccrualRequestDuration observes accrual system calls latency.
+	AccrualRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "request_duration_seconds",
+		Help:      "Accrual system calls latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"code"})
+
+	// UpdaterTickDuration observes order status updater tick duration.
+	UpdaterTickDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "updater",
+		Name:      "tick_duration_seconds",
+		Help:      "Order status updater tick duration.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// UpdaterBatchSize observes number of orders processed by order status updater tick.
+	UpdaterBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "updater",
+		Name:      "batch_size",
+		Help:      "Number of orders processed by order status updater tick.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	// WebhookDeliveriesTotal counts webhook delivery attempts by result.
+	WebhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Number of webhook delivery attempts by result.",
+	}, []string{"result"})
+
+	// EventsPublishedTotal counts outbox events published to the event publisher.
+	EventsPublishedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "published_total",
+		Help:      "Number of outbox events published.",
+	})
+
+	// EventsDeadTotal counts outbox events become dead after failed publishing attempts.
+	EventsDeadTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "dead_total",
+		Help:      "Number of outbox events become dead after failed publishing attempts.",
+	})
+
+	// EmailsSentTotal counts email notification sending attempts by result.
+	EmailsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "sent_total",
+		Help:      "Number of email notification sending attempts by result.",
+	}, []string{"result"})
+
+	// OrdersPending reports number of orders pending by status.
+	OrdersPending = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "orders",
+		Name:      "pending",
+		Help:      "Number of orders pending by status.",
+	}, []string{"status"})
+)
+
+// Register registers collector within the default registry.
+// Collector registered before is ignored.
+func Register(c prometheus.Collector) error {
+	if err := prometheus.Register(c); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler returns metrics exposition handler to be mounted on the API router
+// (compression is left to the router middleware).
+func Handler() http.Handler {
+	return promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+		DisableCompression: true,
+	})
+}
+
+// NewServer returns metrics server listening on separate address.
+func NewServer(config Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.Handler())
+
+	return &http.Server{Addr: config.Address, Handler: mux}
+}
@@ -0,0 +1,58 @@
+package passhash
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// Config keeps password hashing params.
+type Config struct {
+	// Algorithm is used for new hashes, hashes of other algorithms (parameters) are upgraded on login.
+	Algorithm     string `mapstructure:"password_hash_algorithm"`
+	BcryptCost    int    `mapstructure:"password_bcrypt_cost"`
+	Argon2Time    uint32 `mapstructure:"password_argon2_time"`
+	Argon2Memory  uint32 `mapstructure:"password_argon2_memory"`
+	Argon2Threads uint8  `mapstructure:"password_argon2_threads"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	switch config.Algorithm {
+	case AlgorithmArgon2id:
+		if config.Argon2Time < 1 {
+			return fmt.Errorf("password_argon2_time field: must be positive")
+		}
+		if config.Argon2Memory < 8*uint32(config.Argon2Threads) {
+			return fmt.Errorf("password_argon2_memory field: must be at least 8 KiB per thread")
+		}
+		if config.Argon2Threads < 1 {
+			return fmt.Errorf("password_argon2_threads field: must be positive")
+		}
+	case AlgorithmBcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("password_bcrypt_cost field: must be within [%d, %d]", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("password_hash_algorithm field: unknown algorithm: %s", config.Algorithm)
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+// Argon2id defaults follow OWASP recommendations (19 MiB of memory, 2 iterations, 1 thread).
+func NewDefaultConfig() Config {
+	return Config{
+		Algorithm:     AlgorithmArgon2id,
+		BcryptCost:    bcrypt.DefaultCost,
+		Argon2Time:    2,
+		Argon2Memory:  19 * 1024,
+		Argon2Threads: 1,
+	}
+}
@@ -0,0 +1,154 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	argon2idPrefix  = "$argon2id$"
+	argon2SaltLen   = 16
+	argon2KeyLen    = 32
+	argon2idFormat  = "$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s"
+	argon2ParamsFmt = "m=%d,t=%d,p=%d"
+)
+
+var (
+	// ErrMismatch is returned when password doesn't match the hash.
+	ErrMismatch = errors.New("password mismatch")
+	// ErrUnknownFormat is returned when hash format isn't recognized.
+	ErrUnknownFormat = errors.New("unknown hash format")
+)
+
+// Hasher hashes passwords with configured algorithm and verifies hashes of all supported formats.
+// Hash format is identified by its prefix: "$argon2id$" (PHC string format) or "$2a$", "$2b$", "$2y$" (bcrypt).
+type Hasher struct {
+	config Config
+}
+
+// NewHasher creates a new Hasher instance.
+func NewHasher(config Config) (*Hasher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	return &Hasher{config: config}, nil
+}
+
+// Hash hashes password with configured algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	switch h.config.Algorithm {
+	case AlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	default:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		params := argon2Params{
+			memory:  h.config.Argon2Memory,
+			time:    h.config.Argon2Time,
+			threads: h.config.Argon2Threads,
+		}
+		key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeyLen)
+
+		return fmt.Sprintf(argon2idFormat, argon2.Version, params.memory, params.time, params.threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+}
+
+// Verify checks password against the hash of any supported format.
+// It also reports whether the hash should be upgraded to configured algorithm (parameters).
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		params, salt, key, err := parseArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+
+		otherKey := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+			return false, ErrMismatch
+		}
+
+		return h.config.Algorithm != AlgorithmArgon2id ||
+			params.memory != h.config.Argon2Memory ||
+			params.time != h.config.Argon2Time ||
+			params.threads != h.config.Argon2Threads, nil
+	case strings.HasPrefix(hash, "$2"):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrMismatch
+			}
+			return false, err
+		}
+
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return false, err
+		}
+
+		return h.config.Algorithm != AlgorithmBcrypt || cost != h.config.BcryptCost, nil
+	default:
+		return false, ErrUnknownFormat
+	}
+}
+
+// argon2Params keeps argon2 hash parameters.
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// parseArgon2id parses argon2id hash in PHC string format.
+func parseArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return argon2Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrUnknownFormat)
+	}
+
+	var params argon2Params
+	if _, err := fmt.Sscanf(parts[3], argon2ParamsFmt, &params.memory, &params.time, &params.threads); err != nil {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: parsing argon2 params: %v", ErrUnknownFormat, err)
+	}
+	// Zero threads make argon2 panic
+	if params.memory == 0 || params.time == 0 || params.threads == 0 {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: zero argon2 params", ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: decoding salt: %v", ErrUnknownFormat, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: decoding key: %v", ErrUnknownFormat, err)
+	}
+
+	// Empty key would match any password
+	if len(salt) == 0 || len(key) == 0 {
+		return argon2Params{}, nil, nil, fmt.Errorf("%w: empty salt or key", ErrUnknownFormat)
+	}
+
+	return params, salt, key, nil
+}
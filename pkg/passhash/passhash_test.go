@@ -0,0 +1,144 @@
+package passhash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testConfig returns cheap hashing parameters for tests.
+func testConfig(algorithm string) Config {
+	return Config{
+		Algorithm:     algorithm,
+		BcryptCost:    bcrypt.MinCost,
+		Argon2Time:    1,
+		Argon2Memory:  64,
+		Argon2Threads: 1,
+	}
+}
+
+func newTestHasher(t *testing.T, config Config) *Hasher {
+	t.Helper()
+
+	h, err := NewHasher(config)
+	if err != nil {
+		t.Fatalf("NewHasher: %v", err)
+	}
+
+	return h
+}
+
+func TestHasherHashVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmArgon2id, AlgorithmBcrypt} {
+		t.Run(algorithm, func(t *testing.T) {
+			h := newTestHasher(t, testConfig(algorithm))
+
+			hash, err := h.Hash("secret password")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if algorithm == AlgorithmArgon2id && !strings.HasPrefix(hash, argon2idPrefix) {
+				t.Errorf("Hash: got %s, want argon2id PHC string", hash)
+			}
+
+			rehash, err := h.Verify(hash, "secret password")
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if rehash {
+				t.Error("Verify: hash of configured algorithm reported as outdated")
+			}
+
+			if _, err = h.Verify(hash, "wrong password"); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify with wrong password: got %v, want %v", err, ErrMismatch)
+			}
+		})
+	}
+}
+
+func TestHasherVerifyRehash(t *testing.T) {
+	argon2Hash, err := newTestHasher(t, testConfig(AlgorithmArgon2id)).Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	bcryptHash, err := newTestHasher(t, testConfig(AlgorithmBcrypt)).Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	strongerArgon2 := testConfig(AlgorithmArgon2id)
+	strongerArgon2.Argon2Time++
+	strongerBcrypt := testConfig(AlgorithmBcrypt)
+	strongerBcrypt.BcryptCost++
+
+	testCases := []struct {
+		name   string
+		config Config
+		hash   string
+	}{
+		{name: "bcrypt to argon2id", config: testConfig(AlgorithmArgon2id), hash: bcryptHash},
+		{name: "argon2id to bcrypt", config: testConfig(AlgorithmBcrypt), hash: argon2Hash},
+		{name: "argon2id params", config: strongerArgon2, hash: argon2Hash},
+		{name: "bcrypt cost", config: strongerBcrypt, hash: bcryptHash},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rehash, err := newTestHasher(t, tc.config).Verify(tc.hash, "secret")
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !rehash {
+				t.Error("Verify: outdated hash not reported")
+			}
+		})
+	}
+}
+
+func TestHasherVerifyMalformed(t *testing.T) {
+	h := newTestHasher(t, testConfig(AlgorithmArgon2id))
+
+	for _, hash := range []string{
+		"",
+		"plain text",
+		"$argon2id$v=19$m=64,t=1,p=1$salt",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$",
+		"$argon2id$v=19$m=64,t=1,p=1$$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=0$c2FsdA$a2V5",
+	} {
+		if _, err := h.Verify(hash, "secret"); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Verify %q: got %v, want %v", hash, err, ErrUnknownFormat)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := NewDefaultConfig().Validate(); err != nil {
+		t.Errorf("default config: %v", err)
+	}
+
+	invalid := map[string]func(*Config){
+		"unknown algorithm": func(c *Config) { c.Algorithm = "md5" },
+		"zero time":         func(c *Config) { c.Argon2Time = 0 },
+		"low memory":        func(c *Config) { c.Argon2Memory = 7 },
+		"zero threads":      func(c *Config) { c.Argon2Threads = 0 },
+		"low bcrypt cost": func(c *Config) {
+			c.Algorithm = AlgorithmBcrypt
+			c.BcryptCost = bcrypt.MinCost - 1
+		},
+	}
+	for name, modify := range invalid {
+		config := testConfig(AlgorithmArgon2id)
+		modify(&config)
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: config accepted", name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config keeps rate limiting params.
+type Config struct {
+	Enabled        bool          `mapstructure:"rate_limit_enabled"`
+	PublicRate     float64       `mapstructure:"rate_limit_public_rate"`
+	PublicBurst    int           `mapstructure:"rate_limit_public_burst"`
+	ProtectedRate  float64       `mapstructure:"rate_limit_protected_rate"`
+	ProtectedBurst int           `mapstructure:"rate_limit_protected_burst"`
+	IdleTTL        time.Duration `mapstructure:"rate_limit_idle_ttl"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if err := config.PublicLimit().Validate(); err != nil {
+		return fmt.Errorf("rate_limit_public: %w", err)
+	}
+
+	if err := config.ProtectedLimit().Validate(); err != nil {
+		return fmt.Errorf("rate_limit_protected: %w", err)
+	}
+
+	if config.IdleTTL < time.Second {
+		return fmt.Errorf("rate_limit_idle_ttl field: too short period")
+	}
+
+	return nil
+}
+
+// PublicLimit returns limit for public routes.
+func (config Config) PublicLimit() Limit {
+	return Limit{Rate: config.PublicRate, Burst: config.PublicBurst}
+}
+
+// ProtectedLimit returns limit for protected routes.
+func (config Config) ProtectedLimit() Limit {
+	return Limit{Rate: config.ProtectedRate, Burst: config.ProtectedBurst}
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Enabled:        true,
+		PublicRate:     5,
+		PublicBurst:    10,
+		ProtectedRate:  20,
+		ProtectedBurst: 40,
+		IdleTTL:        10 * time.Minute,
+	}
+}
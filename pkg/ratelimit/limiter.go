@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limit keeps token bucket params: refill rate (tokens per second) and bucket size.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Validate performs a basic validation.
+func (l Limit) Validate() error {
+	if l.Rate <= 0 {
+		return fmt.Errorf("rate field: must be positive")
+	}
+
+	if l.Burst < 1 {
+		return fmt.Errorf("burst field: must be positive")
+	}
+
+	return nil
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter keeps token buckets by key.
+type Limiter struct {
+	mu sync.Mutex
+
+	limit       Limit
+	idleTTL     time.Duration
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+// NewLimiter returns a new Limiter instance.
+// Buckets unused for idleTTL are dropped.
+func NewLimiter(limit Limit, idleTTL time.Duration) *Limiter {
+	return &Limiter{
+		limit:       limit,
+		idleTTL:     idleTTL,
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a token from the key bucket.
+// If the bucket is empty, returns false and the time to wait for the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cleanup(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(l.limit.Rate), l.limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, 0
+	}
+
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// cleanup drops idle buckets.
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < l.idleTTL {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
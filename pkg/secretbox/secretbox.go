@@ -0,0 +1,81 @@
+// Package secretbox encrypts short secrets stored at rest with AES-256-GCM.
+// Sealed values are prefixed with a version, so they could be told apart from legacy plaintext ones.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const sealedPrefix = "v1:"
+
+// ErrInvalidSealed is returned when sealed value is malformed or sealed with another key.
+var ErrInvalidSealed = errors.New("invalid sealed value")
+
+// Box seals and opens secrets with a key derived from the configured one.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New creates a new Box instance, AES-256 key is SHA-256 of given key.
+func New(key string) (*Box, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key: empty")
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext with a random nonce.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts sealed value.
+func (b *Box) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", ErrInvalidSealed
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrInvalidSealed
+	}
+
+	nonceSize := b.aead.NonceSize()
+	plaintext, err := b.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], nil)
+	if err != nil {
+		return "", ErrInvalidSealed
+	}
+
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value is sealed (is not a legacy plaintext one).
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
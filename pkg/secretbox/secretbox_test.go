@@ -0,0 +1,77 @@
+package secretbox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBox(t *testing.T) {
+	box, err := New("key")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	otherBox, err := New("other key")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatalf("IsSealed(%q): got false", sealed)
+	}
+	if again, _ := box.Seal("JBSWY3DPEHPK3PXP"); again == sealed {
+		t.Error("sealing the same plaintext twice gives the same value")
+	}
+
+	testCases := []struct {
+		name    string
+		box     *Box
+		sealed  string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", box: box, sealed: sealed, want: "JBSWY3DPEHPK3PXP"},
+		{name: "other key", box: otherBox, sealed: sealed, wantErr: true},
+		{name: "plaintext", box: box, sealed: "JBSWY3DPEHPK3PXP", wantErr: true},
+		{name: "tampered", box: box, sealed: tamper(sealed), wantErr: true},
+		{name: "truncated", box: box, sealed: sealedPrefix + "AAAA", wantErr: true},
+		{name: "malformed", box: box, sealed: sealedPrefix + "!", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.box.Open(tc.sealed)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidSealed) {
+					t.Fatalf("Open: got error %v, want %v", err, ErrInvalidSealed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Open: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewEmptyKey(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Error("New: empty key accepted")
+	}
+}
+
+// tamper replaces the last character of sealed value.
+func tamper(sealed string) string {
+	last := "A"
+	if sealed[len(sealed)-1] == 'A' {
+		last = "B"
+	}
+
+	return sealed[:len(sealed)-1] + last
+}
@@ -0,0 +1,19 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+const randomTokenSize = 32
+
+// NewRandomToken generates a new URL-safe random token.
+func NewRandomToken() (string, error) {
+	b := make([]byte, randomTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
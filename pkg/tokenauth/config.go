@@ -0,0 +1,42 @@
+package tokenauth
+
+import (
+	"fmt"
+
+	"github.com/lestrrat-go/jwx/jwa"
+)
+
+// Config keeps access tokens signing params.
+type Config struct {
+	// Algorithm is a signing algorithm: HS256 (secret_key is used), RS256 or EdDSA.
+	Algorithm string `mapstructure:"jwt_algorithm"`
+	// SigningKey is a PEM encoded private key (has precedence over SigningKeyFile).
+	SigningKey     string `mapstructure:"jwt_signing_key"`
+	SigningKeyFile string `mapstructure:"jwt_signing_key_file"`
+	// VerificationKeys are PEM encoded keys (public or private) which are accepted along with the signing one.
+	// Used for key rotation: previous signing keys should be kept until tokens issued with them are expired.
+	VerificationKeys     []string `mapstructure:"jwt_verification_keys"`
+	VerificationKeyFiles []string `mapstructure:"jwt_verification_key_files"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	switch jwa.SignatureAlgorithm(config.Algorithm) {
+	case jwa.HS256:
+	case jwa.RS256, jwa.EdDSA:
+		if config.SigningKey == "" && config.SigningKeyFile == "" {
+			return fmt.Errorf("jwt_signing_key, jwt_signing_key_file fields: empty")
+		}
+	default:
+		return fmt.Errorf("jwt_algorithm field: unsupported algorithm: %s", config.Algorithm)
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Algorithm: jwa.HS256.String(),
+	}
+}
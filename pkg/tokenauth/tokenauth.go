@@ -0,0 +1,182 @@
+package tokenauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"os"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// TokenAuth signs and verifies JWT access tokens.
+// Every key gets a "kid" (RFC 7638 thumbprint), so tokens are verified with the key they were signed with.
+type TokenAuth struct {
+	alg        jwa.SignatureAlgorithm
+	signingKey jwk.Key
+	keySet     jwk.Set
+	publicSet  jwk.Set
+}
+
+// New creates a new TokenAuth instance.
+// Secret is used as a signing key for HS256 algorithm only.
+func New(config Config, secret string) (*TokenAuth, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	auth := &TokenAuth{
+		alg:       jwa.SignatureAlgorithm(config.Algorithm),
+		keySet:    jwk.NewSet(),
+		publicSet: jwk.NewSet(),
+	}
+
+	var err error
+	if auth.alg == jwa.HS256 {
+		auth.signingKey, err = newKey([]byte(secret), jwa.HS256)
+	} else {
+		auth.signingKey, err = loadKey(config.SigningKey, config.SigningKeyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	if auth.signingKey.Algorithm() != auth.alg.String() {
+		return nil, fmt.Errorf("signing key: %s key can't be used with %s algorithm", auth.signingKey.KeyType(), auth.alg)
+	}
+	if err = auth.addKey(auth.signingKey); err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+
+	for i, data := range config.VerificationKeys {
+		if err = auth.addPEMKey(data, ""); err != nil {
+			return nil, fmt.Errorf("verification key #%d: %w", i, err)
+		}
+	}
+	for _, path := range config.VerificationKeyFiles {
+		if err = auth.addPEMKey("", path); err != nil {
+			return nil, fmt.Errorf("verification key %s: %w", path, err)
+		}
+	}
+
+	return auth, nil
+}
+
+// Encode signs a new token with given claims.
+func (a *TokenAuth) Encode(claims map[string]interface{}) (jwt.Token, string, error) {
+	t := jwt.New()
+	for k, v := range claims {
+		if err := t.Set(k, v); err != nil {
+			return nil, "", fmt.Errorf("setting %s claim: %w", k, err)
+		}
+	}
+
+	payload, err := jwt.Sign(t, a.alg, a.signingKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return t, string(payload), nil
+}
+
+// Decode parses token and verifies its signature with a key matching token "kid" header.
+// Tokens without "kid" are verified with the signing key if it is the only one configured.
+func (a *TokenAuth) Decode(token string) (jwt.Token, error) {
+	return jwt.Parse([]byte(token), jwt.WithKeySet(a.keySet), jwt.UseDefaultKey(true))
+}
+
+// KeyID returns the signing key ID.
+func (a *TokenAuth) KeyID() string {
+	return a.signingKey.KeyID()
+}
+
+// PublicKeys returns JWK set of public verification keys (empty for symmetric algorithm).
+func (a *TokenAuth) PublicKeys() jwk.Set {
+	return a.publicSet
+}
+
+// addPEMKey loads a PEM encoded key and adds it to verification keys.
+func (a *TokenAuth) addPEMKey(data, path string) error {
+	key, err := loadKey(data, path)
+	if err != nil {
+		return err
+	}
+
+	return a.addKey(key)
+}
+
+// addKey adds a key to verification keys and publishes its public part for asymmetric algorithms.
+func (a *TokenAuth) addKey(key jwk.Key) error {
+	if _, ok := a.keySet.LookupKeyID(key.KeyID()); ok {
+		return nil
+	}
+
+	if key.KeyType() == "oct" {
+		a.keySet.Add(key)
+		return nil
+	}
+
+	pubKey, err := jwk.PublicKeyOf(key)
+	if err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+	if err = pubKey.Set(jwk.KeyUsageKey, jwk.ForSignature); err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+
+	a.keySet.Add(pubKey)
+	a.publicSet.Add(pubKey)
+
+	return nil
+}
+
+// loadKey loads a PEM encoded key from data or from a file if data is empty.
+func loadKey(data, path string) (jwk.Key, error) {
+	pemData := []byte(data)
+	if data == "" {
+		var err error
+		if pemData, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+	}
+
+	pemKey, err := jwk.ParseKey(pemData, jwk.WithPEM(true))
+	if err != nil {
+		return nil, fmt.Errorf("parsing PEM: %w", err)
+	}
+
+	var rawKey interface{}
+	if err = pemKey.Raw(&rawKey); err != nil {
+		return nil, fmt.Errorf("parsing PEM: %w", err)
+	}
+
+	var alg jwa.SignatureAlgorithm
+	switch rawKey.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		alg = jwa.RS256
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		alg = jwa.EdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type: %T", rawKey)
+	}
+
+	return newKey(rawKey, alg)
+}
+
+// newKey builds a JWK from raw key and assigns algorithm and ID to it.
+func newKey(rawKey interface{}, alg jwa.SignatureAlgorithm) (jwk.Key, error) {
+	key, err := jwk.New(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = key.Set(jwk.AlgorithmKey, alg); err != nil {
+		return nil, err
+	}
+	if err = jwk.AssignKeyID(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is base32 encoded "12345678901234567890" secret of RFC 6238 test vectors.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B SHA1 test vectors, truncated to 6 digits
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Code at %d: got %s, want %s", tc.unix, got, tc.want)
+			}
+		})
+	}
+
+	if got, err := Code(strings.ToLower(rfcSecret), 1); err != nil || got != "287082" {
+		t.Errorf("Code with lower-cased secret: got %s, %v", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code: malformed secret accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	testCases := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current", code: "050471", wantStep: Step(now), wantOK: true},
+		{name: "previous within skew", code: "081804", skew: 1, wantStep: Step(now) - 1, wantOK: true},
+		{name: "previous without skew", code: "081804"},
+		{name: "wrong", code: "000000", skew: 1},
+		{name: "short", code: "50471", skew: 1},
+		{name: "long", code: "0050471", skew: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok, err := Validate(rfcSecret, tc.code, now, tc.skew)
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if ok != tc.wantOK || step != tc.wantStep {
+				t.Errorf("Validate: got %d, %v, want %d, %v", step, ok, tc.wantStep, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	if _, err = Code(secret, 1); err != nil {
+		t.Errorf("Code with new secret: %v", err)
+	}
+	if other, _ := NewSecret(); other == secret {
+		t.Error("NewSecret: the same secret generated twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	u, err := url.Parse(ProvisioningURI("Gophermart", "apricot", rfcSecret))
+	if err != nil {
+		t.Fatalf("parse URI: %v", err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Gophermart:apricot" {
+		t.Errorf("URI: got %s", u)
+	}
+	query := u.Query()
+	if query.Get("secret") != rfcSecret || query.Get("issuer") != "Gophermart" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("URI params: got %v", query)
+	}
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"fmt"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config keeps tracing params.
+type Config struct {
+	Exporter     string  `mapstructure:"tracing_exporter"`
+	OTLPEndpoint string  `mapstructure:"tracing_otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"tracing_otlp_insecure"`
+	SampleRatio  float64 `mapstructure:"tracing_sample_ratio"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	switch config.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if config.OTLPEndpoint == "" {
+			return fmt.Errorf("tracing_otlp_endpoint field: empty")
+		}
+	default:
+		return fmt.Errorf("tracing_exporter field: unknown exporter: %s", config.Exporter)
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return fmt.Errorf("tracing_sample_ratio field: must be within [0, 1]")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Exporter:     ExporterNone,
+		OTLPEndpoint: "localhost:4318",
+		SampleRatio:  1,
+	}
+}
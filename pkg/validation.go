@@ -0,0 +1,31 @@
+package pkg
+
+import (
+	"strings"
+)
+
+// ValidationError describes a single input field validation failure.
+type ValidationError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationErrors is a set of input validation failures.
+// It matches ErrInvalidInput with errors.Is.
+type ValidationErrors []ValidationError
+
+// Error implements error interface.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Field+": "+err.Message)
+	}
+
+	return ErrInvalidInput.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns ErrInvalidInput.
+func (errs ValidationErrors) Unwrap() error {
+	return ErrInvalidInput
+}
@@ -1,16 +1,27 @@
 package accrual
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
 	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
 	"github.com/vstdy/gophermart/provider/accrual"
 	"github.com/vstdy/gophermart/provider/accrual/http/model"
 )
 
+const (
+	providerName = "accrual"
+)
+
 var _ accrual.Provider = (*Provider)(nil)
 
 // WithConfig sets Config.
@@ -51,19 +62,49 @@ func NewProvider(timeout time.Duration, opts ...ProviderOption) (*Provider, erro
 	prv.client = http.Client{Timeout: timeout}
 	transport := &http.Transport{}
 	transport.MaxIdleConns = 1
-	prv.client.Transport = transport
+	prv.client.Transport = otelhttp.NewTransport(transport)
 
 	return prv, nil
 }
 
+// Ping implements the accrual.Provider interface.
+func (p Provider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.config.AccrualSysAddress, nil)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+
+	r, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("reaching accrual system: %w", err)
+	}
+	defer r.Body.Close()
+
+	return nil
+}
+
 // GetOrderAccruals implements the accrual.Provider interface.
-func (p Provider) GetOrderAccruals(obj canonical.Order) (canonical.Order, error) {
+func (p Provider) GetOrderAccruals(ctx context.Context, obj canonical.Order) (canonical.Order, error) {
 	url := fmt.Sprintf("%s/api/orders/%s", p.config.AccrualSysAddress, obj.Number)
-	r, err := p.client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
+		return canonical.Order{}, fmt.Errorf("building request: %w", err)
+	}
+
+	start := time.Now()
+	r, err := p.client.Do(req)
+	if err != nil {
+		observeRequest(start, "error")
 		return canonical.Order{}, fmt.Errorf("retrieving order object: %w", err)
 	}
 	defer r.Body.Close()
+	observeRequest(start, strconv.Itoa(r.StatusCode))
+
+	logger := p.Logger(ctx)
+	logger.Debug().
+		Int(logging.StatusKey, r.StatusCode).
+		Dur(logging.RequestDurKey, time.Since(start)).
+		Msgf("order %s accruals requested", obj.Number)
 
 	if r.StatusCode != http.StatusOK {
 		return canonical.Order{}, nil
@@ -82,3 +123,16 @@ func (p Provider) GetOrderAccruals(obj canonical.Order) (canonical.Order, error)
 
 	return can, nil
 }
+
+// observeRequest collects accrual system call metrics.
+func observeRequest(start time.Time, code string) {
+	metrics.AccrualRequestsTotal.WithLabelValues(code).Inc()
+	metrics.AccrualRequestDuration.WithLabelValues(code).Observe(time.Since(start).Seconds())
+}
+
+// Logger returns request scoped logger with provider context.
+func (p Provider) Logger(ctx context.Context) zerolog.Logger {
+	logCtx := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, providerName)
+
+	return logCtx.Logger()
+}
@@ -2,10 +2,14 @@
 package accrual
 
 import (
+	"context"
+
 	"github.com/vstdy/gophermart/model"
 )
 
 type Provider interface {
+	// Ping checks accrual system reachability.
+	Ping(ctx context.Context) error
 	// GetOrderAccruals gets order status and accruals.
-	GetOrderAccruals(order model.Order) (model.Order, error)
+	GetOrderAccruals(ctx context.Context, order model.Order) (model.Order, error)
 }
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"fmt"
+)
+
+// Config keeps Publisher params.
+type Config struct {
+	FilePath string `mapstructure:"events_file_path"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	if config.FilePath == "" {
+		return fmt.Errorf("events_file_path field: empty")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		FilePath: "./events.jsonl",
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/provider/events"
+	"github.com/vstdy/gophermart/provider/events/model"
+)
+
+var _ events.Publisher = (*Publisher)(nil)
+
+type (
+	// Publisher appends events to a file as JSON lines, intended for local use.
+	Publisher struct {
+		sync.Mutex
+		config Config
+	}
+
+	// PublisherOption defines functional argument for Publisher constructor.
+	PublisherOption func(*Publisher) error
+)
+
+// WithConfig sets Config.
+func WithConfig(config Config) PublisherOption {
+	return func(p *Publisher) error {
+		p.config = config
+
+		return nil
+	}
+}
+
+// NewPublisher returns a new Publisher instance.
+func NewPublisher(opts ...PublisherOption) (*Publisher, error) {
+	p := &Publisher{
+		config: NewDefaultConfig(),
+	}
+	for optIdx, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, fmt.Errorf("applying option [%d]: %w", optIdx, err)
+		}
+	}
+
+	if err := p.config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	return p, nil
+}
+
+// Publish implements the events.Publisher interface.
+func (p *Publisher) Publish(ctx context.Context, event canonical.Event) error {
+	line, err := json.Marshal(model.NewEventFromCanonical(event))
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	f, err := os.OpenFile(p.config.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements the io.Closer interface.
+func (p *Publisher) Close() error {
+	return nil
+}
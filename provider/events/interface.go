@@ -0,0 +1,17 @@
+//go:generate mockgen -source=interface.go -destination=./mock/publisher.go -package=eventsmock
+package events
+
+import (
+	"context"
+	"io"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type Publisher interface {
+	io.Closer
+
+	// Publish delivers event to the event consumers.
+	// Events are published at least once, consumers are expected to deduplicate them by ID.
+	Publish(ctx context.Context, event model.Event) error
+}
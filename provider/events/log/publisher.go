@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/provider/events"
+)
+
+const (
+	providerName = "events"
+)
+
+var _ events.Publisher = (*Publisher)(nil)
+
+// Publisher writes events to the log, intended for local use.
+type Publisher struct{}
+
+// NewPublisher returns a new Publisher instance.
+func NewPublisher() *Publisher {
+	return &Publisher{}
+}
+
+// Publish implements the events.Publisher interface.
+func (p Publisher) Publish(ctx context.Context, event model.Event) error {
+	logger := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, providerName).Logger()
+	logger.Info().
+		Str("event_id", event.ID.String()).
+		Str("event_type", string(event.Type)).
+		RawJSON("data", event.Payload).
+		Msg("Event published")
+
+	return nil
+}
+
+// Close implements the io.Closer interface.
+func (p Publisher) Close() error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config keeps Publisher params.
+// URL is nats://[user:password@|token@]host[:port], tls:// scheme enforces TLS.
+// Events are published to SubjectPrefix.<event type> subjects.
+// With JetStream enabled events are published through JetStream: a stream must capture the subjects,
+// it deduplicates events by ID within its duplicates window.
+type Config struct {
+	URL           string        `mapstructure:"nats_url"`
+	SubjectPrefix string        `mapstructure:"nats_subject_prefix"`
+	Timeout       time.Duration `mapstructure:"nats_timeout"`
+	JetStream     bool          `mapstructure:"nats_jetstream"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return fmt.Errorf("nats_url field: must be nats:// or tls:// URL")
+	}
+
+	if config.SubjectPrefix == "" || strings.ContainsAny(config.SubjectPrefix, " \t\r\n*>") {
+		return fmt.Errorf("nats_subject_prefix field: must be non-empty subject without whitespaces and wildcards")
+	}
+
+	if config.Timeout < time.Second {
+		return fmt.Errorf("nats_timeout field: too short period")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		URL:           "nats://127.0.0.1:4222",
+		SubjectPrefix: "gophermart",
+		Timeout:       5 * time.Second,
+	}
+}
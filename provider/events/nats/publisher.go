@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/provider/events"
+	"github.com/vstdy/gophermart/provider/events/model"
+)
+
+const clientName = "gophermart"
+
+var _ events.Publisher = (*Publisher)(nil)
+
+type (
+	// Publisher publishes events to NATS.
+	// Core NATS publication is flushed, so the event is known to be accepted by the server,
+	// JetStream publication is acknowledged once the event is stored (and deduplicated by event ID) by the stream.
+	// Connection is established lazily, publications made while reconnecting fail instead of being buffered.
+	Publisher struct {
+		sync.Mutex
+		config Config
+		conn   *nats.Conn
+		js     nats.JetStreamContext
+	}
+
+	// PublisherOption defines functional argument for Publisher constructor.
+	PublisherOption func(*Publisher) error
+)
+
+// WithConfig sets Config.
+func WithConfig(config Config) PublisherOption {
+	return func(p *Publisher) error {
+		p.config = config
+
+		return nil
+	}
+}
+
+// NewPublisher returns a new Publisher instance.
+func NewPublisher(opts ...PublisherOption) (*Publisher, error) {
+	p := &Publisher{
+		config: NewDefaultConfig(),
+	}
+	for optIdx, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, fmt.Errorf("applying option [%d]: %w", optIdx, err)
+		}
+	}
+
+	if err := p.config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	return p, nil
+}
+
+// Publish implements the events.Publisher interface.
+func (p *Publisher) Publish(ctx context.Context, event canonical.Event) error {
+	data, err := json.Marshal(model.NewEventFromCanonical(event))
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn == nil || p.conn.IsClosed() {
+		if err = p.connect(); err != nil {
+			return fmt.Errorf("connecting to NATS: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	msg := &nats.Msg{
+		Subject: p.config.SubjectPrefix + "." + string(event.Type),
+		Data:    data,
+	}
+	if p.js != nil {
+		_, err = p.js.PublishMsg(msg, nats.MsgId(event.ID.String()), nats.Context(ctx))
+	} else if err = p.conn.PublishMsg(msg); err == nil {
+		err = p.conn.FlushWithContext(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("publishing event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// Close implements the io.Closer interface.
+func (p *Publisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+		p.js = nil
+	}
+
+	return nil
+}
+
+// connect establishes connection authenticated with URL credentials (TLS is handled by the client).
+func (p *Publisher) connect() error {
+	conn, err := nats.Connect(
+		p.config.URL,
+		nats.Name(clientName),
+		nats.Timeout(p.config.Timeout),
+		nats.ReconnectBufSize(-1),
+	)
+	if err != nil {
+		return err
+	}
+
+	var js nats.JetStreamContext
+	if p.config.JetStream {
+		if js, err = conn.JetStream(); err != nil {
+			conn.Close()
+			return fmt.Errorf("JetStream context: %w", err)
+		}
+	}
+
+	p.conn = conn
+	p.js = js
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package notifier
+
+import (
+	"fmt"
+)
+
+// Config keeps Notifier params.
+type Config struct {
+	FilePath string `mapstructure:"notifier_file_path"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	if config.FilePath == "" {
+		return fmt.Errorf("notifier_file_path field: empty")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		FilePath: "./messages.jsonl",
+	}
+}
@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/provider/notifier"
+)
+
+var _ notifier.Notifier = (*Notifier)(nil)
+
+type (
+	// Notifier appends messages to a file as JSON lines, intended for local use.
+	Notifier struct {
+		sync.Mutex
+		config Config
+	}
+
+	// NotifierOption defines functional argument for Notifier constructor.
+	NotifierOption func(*Notifier) error
+
+	// fileMessage is a message file entry.
+	fileMessage struct {
+		To      string    `json:"to"`
+		Subject string    `json:"subject"`
+		Body    string    `json:"body"`
+		SentAt  time.Time `json:"sent_at"`
+	}
+)
+
+// WithConfig sets Config.
+func WithConfig(config Config) NotifierOption {
+	return func(n *Notifier) error {
+		n.config = config
+
+		return nil
+	}
+}
+
+// NewNotifier returns a new Notifier instance.
+func NewNotifier(opts ...NotifierOption) (*Notifier, error) {
+	n := &Notifier{
+		config: NewDefaultConfig(),
+	}
+	for optIdx, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, fmt.Errorf("applying option [%d]: %w", optIdx, err)
+		}
+	}
+
+	if err := n.config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	return n, nil
+}
+
+// Send implements the notifier.Notifier interface.
+func (n *Notifier) Send(ctx context.Context, msg model.Message) error {
+	line, err := json.Marshal(fileMessage{
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		SentAt:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	f, err := os.OpenFile(n.config.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:generate mockgen -source=interface.go -destination=./mock/notifier.go -package=notifiermock
+package notifier
+
+import (
+	"context"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type Notifier interface {
+	// Send delivers message to its recipient.
+	Send(ctx context.Context, msg model.Message) error
+}
@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/provider/notifier"
+)
+
+const (
+	providerName = "notifier"
+)
+
+var _ notifier.Notifier = (*Notifier)(nil)
+
+// Notifier writes messages to the log, intended for local use.
+type Notifier struct{}
+
+// NewNotifier returns a new Notifier instance.
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Send implements the notifier.Notifier interface.
+func (n Notifier) Send(ctx context.Context, msg model.Message) error {
+	logger := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, providerName).Logger()
+	logger.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Body).
+		Msg("Message sent")
+
+	return nil
+}
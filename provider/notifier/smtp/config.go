@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"fmt"
+	"net/mail"
+	"time"
+)
+
+const (
+	// SecurityNone sends messages over plain connection, intended for local SMTP sinks.
+	SecurityNone = "none"
+	// SecurityStartTLS upgrades plain connection with STARTTLS.
+	SecurityStartTLS = "starttls"
+	// SecurityTLS connects over implicit TLS.
+	SecurityTLS = "tls"
+)
+
+// Config keeps Notifier params.
+// Server is authenticated with Username and Password if Username is set.
+type Config struct {
+	Host     string        `mapstructure:"smtp_host"`
+	Port     int           `mapstructure:"smtp_port"`
+	Username string        `mapstructure:"smtp_username"`
+	Password string        `mapstructure:"smtp_password"`
+	From     string        `mapstructure:"smtp_from"`
+	Security string        `mapstructure:"smtp_security"`
+	Timeout  time.Duration `mapstructure:"smtp_timeout"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	if config.Host == "" {
+		return fmt.Errorf("smtp_host field: empty")
+	}
+
+	if config.Port < 1 || config.Port > 65535 {
+		return fmt.Errorf("smtp_port field: must be in range [1, 65535]")
+	}
+
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return fmt.Errorf("smtp_from field: %w", err)
+	}
+
+	switch config.Security {
+	case SecurityNone, SecurityStartTLS, SecurityTLS:
+	default:
+		return fmt.Errorf("smtp_security field: must be one of %s, %s, %s", SecurityNone, SecurityStartTLS, SecurityTLS)
+	}
+
+	if config.Username != "" && config.Security == SecurityNone {
+		return fmt.Errorf("smtp_username field: credentials can't be sent over plain connection")
+	}
+
+	if config.Timeout < time.Second {
+		return fmt.Errorf("smtp_timeout field: too short period")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Host:     "127.0.0.1",
+		Port:     1025,
+		From:     "Gophermart <noreply@gophermart.local>",
+		Security: SecurityNone,
+		Timeout:  10 * time.Second,
+	}
+}
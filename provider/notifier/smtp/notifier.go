@@ -0,0 +1,205 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/provider/notifier"
+)
+
+var _ notifier.Notifier = (*Notifier)(nil)
+
+type (
+	// Notifier sends messages as plain text emails via SMTP server.
+	// Connection is made per message, so no state is kept between sends.
+	Notifier struct {
+		config Config
+		from   *mail.Address
+	}
+
+	// NotifierOption defines functional argument for Notifier constructor.
+	NotifierOption func(*Notifier) error
+)
+
+// WithConfig sets Config.
+func WithConfig(config Config) NotifierOption {
+	return func(n *Notifier) error {
+		n.config = config
+
+		return nil
+	}
+}
+
+// NewNotifier returns a new Notifier instance.
+func NewNotifier(opts ...NotifierOption) (*Notifier, error) {
+	n := &Notifier{
+		config: NewDefaultConfig(),
+	}
+	for optIdx, opt := range opts {
+		if err := opt(n); err != nil {
+			return nil, fmt.Errorf("applying option [%d]: %w", optIdx, err)
+		}
+	}
+
+	if err := n.config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	from, err := mail.ParseAddress(n.config.From)
+	if err != nil {
+		return nil, fmt.Errorf("parsing sender address: %w", err)
+	}
+	n.from = from
+
+	return n, nil
+}
+
+// Send implements the notifier.Notifier interface.
+func (n *Notifier) Send(ctx context.Context, msg model.Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("parsing recipient address: %w", err)
+	}
+
+	data, err := n.buildMessage(to, msg)
+	if err != nil {
+		return fmt.Errorf("building message: %w", err)
+	}
+
+	deadline := time.Now().Add(n.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	client, err := n.dial(ctx, deadline)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err = client.Mail(n.from.Address); err != nil {
+		return fmt.Errorf("MAIL FROM: %w", err)
+	}
+	if err = client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("RCPT TO: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("DATA: %w", err)
+	}
+
+	if err = client.Quit(); err != nil {
+		return fmt.Errorf("QUIT: %w", err)
+	}
+
+	return nil
+}
+
+// dial connects to SMTP server, secures the connection and authenticates according to config.
+// The whole SMTP session is limited by deadline.
+func (n *Notifier) dial(ctx context.Context, deadline time.Time) (*smtp.Client, error) {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	tlsConfig := &tls.Config{ServerName: n.config.Host, MinVersion: tls.VersionTLS12}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("setting deadline: %w", err)
+	}
+
+	if n.config.Security == SecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("greeting: %w", err)
+	}
+
+	if n.config.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("server doesn't support STARTTLS")
+		}
+		if err = client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err = client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("AUTH: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// buildMessage builds RFC 5322 message with UTF-8 quoted-printable plain text body.
+func (n *Notifier) buildMessage(to *mail.Address, msg model.Message) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := n.from.Address[strings.LastIndex(n.from.Address, "@")+1:]
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", n.from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", stripNewlines(msg.Subject))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=UTF-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+	}
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// stripNewlines replaces line breaks with spaces, so header values can't inject headers.
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
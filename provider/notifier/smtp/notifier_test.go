@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+func TestNotifierBuildMessage(t *testing.T) {
+	n, err := NewNotifier()
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	to := &mail.Address{Address: "apricot@example.com"}
+	data, err := n.buildMessage(to, model.Message{
+		To:      to.Address,
+		Subject: "Пароль\r\nBcc: eve@example.com",
+		Body:    "Token: 42\nExpires soon — " + strings.Repeat("x", 100),
+	})
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+
+	if got := msg.Header.Get("Bcc"); got != "" {
+		t.Errorf("Bcc header injected: %s", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decoding subject: %v", err)
+	}
+	if subject != "Пароль Bcc: eve@example.com" {
+		t.Errorf("Subject: got %q", subject)
+	}
+	if got := msg.Header.Get("From"); got != `"Gophermart" <noreply@gophermart.local>` {
+		t.Errorf("From: got %s", got)
+	}
+	if got := msg.Header.Get("To"); got != "<apricot@example.com>" {
+		t.Errorf("To: got %s", got)
+	}
+	if got := msg.Header.Get("Message-ID"); !strings.HasSuffix(got, "@gophermart.local>") {
+		t.Errorf("Message-ID: got %s", got)
+	}
+	if _, err = msg.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if want := "Token: 42\r\nExpires soon — " + strings.Repeat("x", 100); string(body) != want {
+		t.Errorf("body: got %q, want %q", body, want)
+	}
+	rawBody := data[bytes.Index(data, []byte("\r\n\r\n"))+4:]
+	for _, line := range strings.Split(string(rawBody), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("body line is longer than 76 characters: %q", line)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := NewDefaultConfig().Validate(); err != nil {
+		t.Errorf("default config: %v", err)
+	}
+
+	invalid := map[string]func(*Config){
+		"no host":           func(c *Config) { c.Host = "" },
+		"port out of range": func(c *Config) { c.Port = 65536 },
+		"no sender":         func(c *Config) { c.From = "" },
+		"unknown security":  func(c *Config) { c.Security = "ssl" },
+		"plain credentials": func(c *Config) { c.Username = "apricot" },
+		"short timeout":     func(c *Config) { c.Timeout = 0 },
+	}
+	for name, modify := range invalid {
+		config := NewDefaultConfig()
+		modify(&config)
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: config accepted", name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config keeps Sender params.
+type Config struct {
+	Timeout time.Duration `mapstructure:"webhook_request_timeout"`
+}
+
+// Validate performs a basic validation.
+func (config Config) Validate() error {
+	if config.Timeout < time.Second {
+		return fmt.Errorf("webhook_request_timeout field: too short period")
+	}
+
+	return nil
+}
+
+// NewDefaultConfig builds a Config with default values.
+func NewDefaultConfig() Config {
+	return Config{
+		Timeout: 10 * time.Second,
+	}
+}
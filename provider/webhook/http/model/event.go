@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// Event keeps webhook request body data.
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEventFromCanonical creates a new Event from canonical model.
+func NewEventFromCanonical(obj model.Event) Event {
+	return Event{
+		ID:        obj.ID,
+		Type:      string(obj.Type),
+		CreatedAt: obj.CreatedAt,
+		Data:      obj.Payload,
+	}
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	canonical "github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/provider/webhook"
+	"github.com/vstdy/gophermart/provider/webhook/http/model"
+)
+
+const (
+	providerName = "webhook"
+
+	userAgent       = "Gophermart-Webhook/1.0"
+	headerEvent     = "X-Gophermart-Event"
+	headerEventID   = "X-Gophermart-Event-Id"
+	headerDelivery  = "X-Gophermart-Delivery"
+	headerTimestamp = "X-Gophermart-Timestamp"
+	headerSignature = "X-Gophermart-Signature"
+
+	// maxResponseSize limits response body read to reuse the connection.
+	maxResponseSize = 64 << 10
+)
+
+var _ webhook.Sender = (*Sender)(nil)
+
+// WithConfig sets Config.
+func WithConfig(config Config) SenderOption {
+	return func(s *Sender) error {
+		s.config = config
+
+		return nil
+	}
+}
+
+type (
+	// Sender posts webhook requests over HTTP.
+	Sender struct {
+		config Config
+		client http.Client
+	}
+
+	// SenderOption defines functional argument for Sender constructor.
+	SenderOption func(*Sender) error
+)
+
+// NewSender returns a new Sender instance.
+func NewSender(opts ...SenderOption) (*Sender, error) {
+	s := &Sender{
+		config: NewDefaultConfig(),
+	}
+	for optIdx, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("applying option [%d]: %w", optIdx, err)
+		}
+	}
+
+	if err := s.config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
+	s.client = http.Client{
+		Timeout:   s.config.Timeout,
+		Transport: otelhttp.NewTransport(&http.Transport{}),
+		// Redirects aren't followed, so the signed request is only sent to the configured URL
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return s, nil
+}
+
+// Send implements the webhook.Sender interface.
+func (s Sender) Send(ctx context.Context, msg canonical.WebhookMessage) (int, error) {
+	body, err := json.Marshal(model.NewEventFromCanonical(msg.Event))
+	if err != nil {
+		return 0, fmt.Errorf("encoding event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(headerEvent, string(msg.Event.Type))
+	req.Header.Set(headerEventID, msg.Event.ID.String())
+	req.Header.Set(headerDelivery, msg.DeliveryID.String())
+	req.Header.Set(headerTimestamp, timestamp)
+	req.Header.Set(headerSignature, "sha256="+sign(msg.Secret, timestamp, body))
+
+	start := time.Now()
+	r, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("sending request: %w", err)
+	}
+	defer r.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(r.Body, maxResponseSize))
+
+	logger := s.Logger(ctx)
+	logger.Debug().
+		Int(logging.StatusKey, r.StatusCode).
+		Dur(logging.RequestDurKey, time.Since(start)).
+		Msgf("webhook %s delivery %s sent", msg.Event.Type, msg.DeliveryID)
+
+	if r.StatusCode < http.StatusOK || r.StatusCode >= http.StatusMultipleChoices {
+		return r.StatusCode, fmt.Errorf("unexpected response status: %s", r.Status)
+	}
+
+	return r.StatusCode, nil
+}
+
+// sign returns hex encoded HMAC-SHA256 of the timestamp and the body joined with a dot.
+// Timestamp is signed, so receivers can reject replayed requests.
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Logger returns request scoped logger with provider context.
+func (s Sender) Logger(ctx context.Context) zerolog.Logger {
+	logCtx := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, providerName)
+
+	return logCtx.Logger()
+}
@@ -0,0 +1,14 @@
+//go:generate mockgen -source=interface.go -destination=./mock/sender.go -package=webhookmock
+package webhook
+
+import (
+	"context"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type Sender interface {
+	// Send posts signed event to the webhook URL.
+	// Returns receiver response status code (zero if no response), non-2xx responses are errors.
+	Send(ctx context.Context, msg model.WebhookMessage) (int, error)
+}
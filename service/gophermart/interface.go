@@ -3,6 +3,7 @@ package gophermart
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -12,10 +13,64 @@ import (
 type Service interface {
 	io.Closer
 
+	// CheckReadiness checks service dependencies health.
+	CheckReadiness(ctx context.Context) []model.HealthCheck
+
 	// CreateUser creates a new model.User.
 	CreateUser(ctx context.Context, obj model.User) (model.User, error)
 	// AuthenticateUser verifies the identity of credentials.
-	AuthenticateUser(ctx context.Context, obj model.User) (model.User, error)
+	AuthenticateUser(ctx context.Context, obj model.User, client model.ClientInfo) (model.User, error)
+	// ChangePassword changes access token user password, revokes all user sessions and the access token.
+	ChangePassword(ctx context.Context, accessToken model.AccessToken, currentPassword, newPassword string) error
+	// RequestPasswordReset issues a password reset token and sends it to the user.
+	RequestPasswordReset(ctx context.Context, login string) error
+	// ResetPassword sets user password by password reset token and revokes all user sessions.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// DeleteUser deletes access token user after password confirmation, revokes all user sessions and the access token.
+	DeleteUser(ctx context.Context, accessToken model.AccessToken, password string) error
+	// EnrollTwoFactor starts TOTP two-factor authentication enrolment of given user.
+	EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (model.TwoFactorEnrollment, error)
+	// ConfirmTwoFactor enables TOTP two-factor authentication of given user and returns recovery codes.
+	ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// VerifyTwoFactor completes login of challenge user verifying TOTP or recovery code, the challenge is consumed.
+	VerifyTwoFactor(ctx context.Context, challenge model.TwoFactorChallenge, code string, client model.ClientInfo) (model.User, error)
+	// DisableTwoFactor disables two-factor authentication of given user verifying password and TOTP or recovery code.
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, password, code string, client model.ClientInfo) error
+	// SetUserRole sets role of given user.
+	SetUserRole(ctx context.Context, userID uuid.UUID, role model.Role) (model.User, error)
+	// SetUserRoleByLogin sets role of user with given login.
+	SetUserRoleByLogin(ctx context.Context, login string, role model.Role) (model.User, error)
+	// UnlockLogin resets failed login attempts and lockout for given login.
+	UnlockLogin(ctx context.Context, login string) error
+
+	// FindUser gets user by ID or login.
+	FindUser(ctx context.Context, ref string) (model.User, error)
+	// GetProfile gets user with profile data.
+	GetProfile(ctx context.Context, userID uuid.UUID) (model.User, error)
+	// UpdateProfile sets given user profile fields (clears the empty ones), nil fields are kept unchanged.
+	UpdateProfile(ctx context.Context, userID uuid.UUID, obj model.ProfileUpdate) (model.User, error)
+
+	// CreateAPIKey issues a new merchant API key with given scopes, zero ttl means the key never expires.
+	CreateAPIKey(ctx context.Context, name string, scopes []model.APIKeyScope, ttl time.Duration) (model.APIKey, error)
+	// GetAPIKeys gets all merchant API keys.
+	GetAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	// RevokeAPIKey revokes merchant API key by its id.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// AuthenticateAPIKey gets active merchant API key by the key.
+	AuthenticateAPIKey(ctx context.Context, key string) (model.APIKey, error)
+
+	// CreateSession creates a new refresh token session for given user.
+	CreateSession(ctx context.Context, userID uuid.UUID, client model.ClientInfo) (model.Session, error)
+	// RefreshSession rotates session refresh token.
+	RefreshSession(ctx context.Context, refreshToken string, client model.ClientInfo) (model.Session, error)
+	// GetSessions gets active user sessions.
+	GetSessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	// RevokeUserSession revokes user session by its id.
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	// Logout revokes session and access token, refresh token must belong to the access token user.
+	Logout(ctx context.Context, refreshToken string, accessToken model.AccessToken) error
+	// ValidateAccessToken checks that access token and its session are not revoked and returns current user role.
+	ValidateAccessToken(ctx context.Context, accessToken model.AccessToken) (model.Role, error)
 
 	// AddOrder adds given order to storage.
 	AddOrder(ctx context.Context, obj model.Order) (model.Order, error)
@@ -28,4 +83,33 @@ type Service interface {
 	AddWithdrawal(ctx context.Context, transaction model.Transaction) error
 	// GetWithdrawals gets current user withdrawals.
 	GetWithdrawals(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error)
+
+	// SearchUsers gets users whose login contains query, zero limit means the default one.
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]model.User, error)
+	// AddAdjustment adds manual balance adjustment of user made by operator.
+	AddAdjustment(ctx context.Context, operatorID, userID uuid.UUID, amount float32, reason string) (model.Transaction, error)
+	// GetAdjustments gets user manual balance adjustments.
+	GetAdjustments(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error)
+
+	// VerifyAuditLog verifies audit log hash chain and reports the first broken link.
+	VerifyAuditLog(ctx context.Context) (model.AuditVerification, error)
+
+	// GetWebhookDeliveries gets webhook deliveries with given status (any if empty), latest first.
+	GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error)
+	// ReplayWebhookDeliveries schedules given webhook deliveries (all dead ones if none given) for immediate delivery.
+	ReplayWebhookDeliveries(ctx context.Context, ids []uuid.UUID) (int, error)
+
+	// GetNotificationSettings gets user notification settings.
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (model.NotificationSettings, error)
+	// SetNotificationSettings sets opt-in preferences of given notification types and changes user email:
+	// the current password is required for that, a new email is set once verified.
+	SetNotificationSettings(ctx context.Context, userID uuid.UUID, obj model.NotificationSettings, password string) (model.NotificationSettings, error)
+	// VerifyEmail sets user email to the one email verification token is sent to.
+	VerifyEmail(ctx context.Context, userID uuid.UUID, token string) error
+	// GetNotifications gets user inbox notifications (unread ones only if unreadOnly is set), latest first,
+	// along with user notification counters.
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (model.NotificationList, error)
+	// MarkNotificationsRead marks given user inbox notifications (all if none given) read.
+	// Returns the number of user unread notifications left.
+	MarkNotificationsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int, error)
 }
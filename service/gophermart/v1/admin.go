@@ -0,0 +1,95 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchUsers gets users whose login contains query, zero limit means the default one.
+func (svc *Service) SearchUsers(ctx context.Context, query string, limit, offset int) (_ []model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.SearchUsers")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit || offset < 0 {
+		return nil, fmt.Errorf("%w: limit must be within [1, %d], offset must not be negative", pkg.ErrInvalidInput, maxSearchLimit)
+	}
+
+	objs, err := svc.storage.SearchUsers(ctx, validator.NormalizeLogin(query), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("searching users: %w", err)
+	}
+
+	return objs, nil
+}
+
+// AddAdjustment adds manual balance adjustment of user made by operator:
+// positive amount credits the balance, negative one debits it. Operators can't adjust their own balance.
+func (svc *Service) AddAdjustment(ctx context.Context, operatorID, userID uuid.UUID, amount float32, reason string) (_ model.Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.AddAdjustment")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if errs := validator.ValidateAdjustment(amount, reason); len(errs) > 0 {
+		return model.Transaction{}, errs
+	}
+
+	if operatorID == userID {
+		return model.Transaction{}, fmt.Errorf("adjusting own balance: %w", pkg.ErrForbidden)
+	}
+
+	if _, err := svc.storage.GetUser(ctx, userID); err != nil {
+		return model.Transaction{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	rawObj := model.Transaction{
+		UserID:     userID,
+		OperatorID: operatorID,
+		Reason:     strings.TrimSpace(reason),
+	}
+	if amount > 0 {
+		rawObj.Accrual = amount
+	} else {
+		rawObj.Withdrawal = -amount
+	}
+
+	obj, err := svc.storage.AddAdjustment(ctx, rawObj)
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("adding adjustment: %w", err)
+	}
+
+	return obj, nil
+}
+
+// GetAdjustments gets user manual balance adjustments.
+func (svc *Service) GetAdjustments(ctx context.Context, userID uuid.UUID) (_ []model.Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetAdjustments")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	objs, err := svc.storage.GetAdjustments(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting adjustments: %w", err)
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,21 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/pkg"
+)
+
+func TestServiceAddAdjustmentOwnBalance(t *testing.T) {
+	svc := &Service{}
+	operatorID := uuid.New()
+
+	_, err := svc.AddAdjustment(context.Background(), operatorID, operatorID, 50, "Bonus")
+	if !errors.Is(err, pkg.ErrForbidden) {
+		t.Errorf("AddAdjustment: got error %v, want %v", err, pkg.ErrForbidden)
+	}
+}
@@ -0,0 +1,142 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+const (
+	apiKeyPrefix       = "gm_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+	apiKeyMaxNameLen   = 100
+)
+
+// CreateAPIKey issues a new merchant API key with given scopes, zero ttl means the key never expires.
+// The key itself is only returned here and is stored hashed.
+func (svc *Service) CreateAPIKey(ctx context.Context, name string, scopes []model.APIKeyScope, ttl time.Duration) (_ model.APIKey, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.CreateAPIKey")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if name == "" || len([]rune(name)) > apiKeyMaxNameLen {
+		return model.APIKey{}, fmt.Errorf("%w: name must be non-empty and at most %d characters long", pkg.ErrInvalidInput, apiKeyMaxNameLen)
+	}
+	if len(scopes) == 0 {
+		return model.APIKey{}, fmt.Errorf("%w: at least one scope is required", pkg.ErrInvalidInput)
+	}
+	for _, scope := range scopes {
+		if err := scope.Validate(); err != nil {
+			return model.APIKey{}, fmt.Errorf("%w: %v", pkg.ErrInvalidInput, err)
+		}
+	}
+	if ttl < 0 {
+		return model.APIKey{}, fmt.Errorf("%w: ttl must not be negative", pkg.ErrInvalidInput)
+	}
+
+	token, err := pkg.NewRandomToken()
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	key := apiKeyPrefix + token
+
+	rawObj := model.APIKey{
+		Name:   name,
+		Key:    key,
+		Prefix: key[:apiKeyPrefixLength],
+		Scopes: scopes,
+	}
+	if ttl > 0 {
+		rawObj.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	obj, err := svc.storage.CreateAPIKey(ctx, rawObj)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("creating API key: %w", err)
+	}
+
+	return obj, nil
+}
+
+// GetAPIKeys gets all merchant API keys.
+func (svc *Service) GetAPIKeys(ctx context.Context) (_ []model.APIKey, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetAPIKeys")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	objs, err := svc.storage.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting API keys: %w", err)
+	}
+
+	return objs, nil
+}
+
+// RevokeAPIKey revokes merchant API key by its id.
+func (svc *Service) RevokeAPIKey(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.RevokeAPIKey")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if err := svc.storage.RevokeAPIKey(ctx, id); err != nil {
+		return fmt.Errorf("revoking API key: %w", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey gets active merchant API key by the key.
+func (svc *Service) AuthenticateAPIKey(ctx context.Context, key string) (_ model.APIKey, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.AuthenticateAPIKey")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if key == "" {
+		return model.APIKey{}, pkg.ErrInvalidToken
+	}
+
+	obj, err := svc.storage.AuthenticateAPIKey(ctx, key)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("authenticating API key: %w", err)
+	}
+
+	return obj, nil
+}
+
+// FindUser gets user by ID or login.
+// References parsed as UUID are looked up by ID first, as logins might look like UUIDs as well.
+func (svc *Service) FindUser(ctx context.Context, ref string) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.FindUser")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if userID, err := uuid.Parse(ref); err == nil {
+		obj, err := svc.storage.GetUser(ctx, userID)
+		if err == nil {
+			return obj, nil
+		}
+		if !errors.Is(err, pkg.ErrNotFound) {
+			return model.User{}, fmt.Errorf("getting user: %w", err)
+		}
+	}
+
+	obj, err := svc.storage.GetUserByLogin(ctx, validator.NormalizeLogin(ref))
+	if err != nil {
+		return model.User{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	return obj, nil
+}
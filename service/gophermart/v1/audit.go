@@ -0,0 +1,24 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+// VerifyAuditLog verifies audit log hash chain and reports the first broken link.
+func (svc *Service) VerifyAuditLog(ctx context.Context) (_ model.AuditVerification, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.VerifyAuditLog")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	result, err := svc.storage.VerifyAuditLog(ctx)
+	if err != nil {
+		return model.AuditVerification{}, fmt.Errorf("verifying audit log: %w", err)
+	}
+
+	return result, nil
+}
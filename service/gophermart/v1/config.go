@@ -2,14 +2,116 @@ package gophermart
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
 )
 
-// Config keeps Service params.
-type Config struct {
-	UpdaterTimeout      time.Duration `mapstructure:"updater_timeout"`
-	StatusCheckInterval time.Duration `mapstructure:"status_check_interval"`
-}
+type (
+	// Config keeps Service params.
+	Config struct {
+		UpdaterTimeout        time.Duration            `mapstructure:"updater_timeout"`
+		StatusCheckInterval   time.Duration            `mapstructure:"status_check_interval"`
+		ReadinessCheckAccrual bool                     `mapstructure:"readiness_check_accrual"`
+		RefreshTokenTTL       time.Duration            `mapstructure:"refresh_token_ttl"`
+		PasswordResetTTL      time.Duration            `mapstructure:"password_reset_ttl"`
+		EmailVerificationTTL  time.Duration            `mapstructure:"email_verification_ttl"`
+		LoginPolicy           validator.LoginPolicy    `mapstructure:"login_policy,squash"`
+		PasswordPolicy        validator.PasswordPolicy `mapstructure:"password_policy,squash"`
+		LoginThrottle         LoginThrottleConfig      `mapstructure:"login_throttle,squash"`
+		TwoFactor             TwoFactorConfig          `mapstructure:"two_factor,squash"`
+		Webhook               WebhookConfig            `mapstructure:"webhook,squash"`
+		EventRelay            EventRelayConfig         `mapstructure:"event_relay,squash"`
+		Email                 EmailConfig              `mapstructure:"email,squash"`
+		Inbox                 InboxConfig              `mapstructure:"inbox,squash"`
+	}
+
+	// InboxConfig keeps in-app notifications params.
+	// Writer adds notifications of up to BatchSize outbox events every Interval within Timeout.
+	// Notifications older than Retention are deleted every CleanupInterval.
+	InboxConfig struct {
+		Interval        time.Duration `mapstructure:"inbox_interval"`
+		Timeout         time.Duration `mapstructure:"inbox_timeout"`
+		BatchSize       int           `mapstructure:"inbox_batch_size"`
+		Retention       time.Duration `mapstructure:"inbox_retention"`
+		CleanupInterval time.Duration `mapstructure:"inbox_cleanup_interval"`
+	}
+
+	// EmailConfig keeps email notifications dispatching params.
+	// Dispatcher sends up to BatchSize emails every DispatchInterval within DispatchTimeout.
+	// Failed emails are retried with exponentially growing delay (RetryBase doubling up to RetryMax)
+	// and fail after MaxAttempts attempts.
+	EmailConfig struct {
+		DispatchInterval time.Duration `mapstructure:"email_dispatch_interval"`
+		DispatchTimeout  time.Duration `mapstructure:"email_dispatch_timeout"`
+		BatchSize        int           `mapstructure:"email_batch_size"`
+		MaxAttempts      int           `mapstructure:"email_max_attempts"`
+		RetryBase        time.Duration `mapstructure:"email_retry_base"`
+		RetryMax         time.Duration `mapstructure:"email_retry_max"`
+	}
+
+	// EventRelayConfig keeps outbox events relay params.
+	// Relay publishes up to BatchSize events every Interval within Timeout
+	// (events are leased for Timeout, so concurrent relays don't publish them too).
+	// Events failed to be published MaxAttempts times become dead.
+	EventRelayConfig struct {
+		Interval    time.Duration `mapstructure:"event_relay_interval"`
+		Timeout     time.Duration `mapstructure:"event_relay_timeout"`
+		BatchSize   int           `mapstructure:"event_relay_batch_size"`
+		MaxAttempts int           `mapstructure:"event_relay_max_attempts"`
+	}
+
+	// WebhookConfig keeps webhook subscriptions and deliveries dispatching params.
+	// Dispatcher delivers up to BatchSize deliveries every DispatchInterval within DispatchTimeout.
+	// Failed deliveries are retried with exponentially growing delay (RetryBase doubling up to RetryMax)
+	// and become dead after MaxAttempts attempts.
+	WebhookConfig struct {
+		Subscriptions    []WebhookSubscription `mapstructure:"webhooks"`
+		DispatchInterval time.Duration         `mapstructure:"webhook_dispatch_interval"`
+		DispatchTimeout  time.Duration         `mapstructure:"webhook_dispatch_timeout"`
+		BatchSize        int                   `mapstructure:"webhook_batch_size"`
+		MaxAttempts      int                   `mapstructure:"webhook_max_attempts"`
+		RetryBase        time.Duration         `mapstructure:"webhook_retry_base"`
+		RetryMax         time.Duration         `mapstructure:"webhook_retry_max"`
+	}
+
+	// WebhookSubscription keeps webhook subscription params.
+	// Requests are signed with Secret, Name identifies the subscription deliveries.
+	WebhookSubscription struct {
+		Name   string            `mapstructure:"name"`
+		URL    string            `mapstructure:"url"`
+		Secret string            `mapstructure:"secret"`
+		Events []model.EventType `mapstructure:"events"`
+	}
+
+	// TwoFactorConfig keeps TOTP two-factor authentication params.
+	// Skew is a number of code periods codes are accepted before and after the current one (clock drift).
+	// Secrets are stored encrypted with EncryptionKey.
+	TwoFactorConfig struct {
+		Issuer        string `mapstructure:"totp_issuer"`
+		Skew          int    `mapstructure:"totp_skew"`
+		RecoveryCodes int    `mapstructure:"totp_recovery_codes"`
+		EncryptionKey string `mapstructure:"totp_encryption_key"`
+	}
+
+	// LoginThrottleConfig keeps failed login attempts throttling params.
+	// Failures are counted per login and per client IP within the window.
+	// Login is delayed (exponentially growing) after DelayThreshold failures and locked out after LockoutThreshold ones,
+	// client IP is locked out after IPLockoutThreshold failures.
+	LoginThrottleConfig struct {
+		Enabled            bool          `mapstructure:"login_throttle_enabled"`
+		Window             time.Duration `mapstructure:"login_throttle_window"`
+		DelayThreshold     int           `mapstructure:"login_throttle_delay_threshold"`
+		DelayBase          time.Duration `mapstructure:"login_throttle_delay_base"`
+		DelayMax           time.Duration `mapstructure:"login_throttle_delay_max"`
+		LockoutThreshold   int           `mapstructure:"login_throttle_lockout_threshold"`
+		IPLockoutThreshold int           `mapstructure:"login_throttle_ip_lockout_threshold"`
+		LockoutDuration    time.Duration `mapstructure:"login_throttle_lockout_duration"`
+	}
+)
 
 // Validate performs a basic validation.
 func (config Config) Validate() error {
@@ -21,13 +123,315 @@ func (config Config) Validate() error {
 		return fmt.Errorf("status_check_interval field: too short period")
 	}
 
+	if config.RefreshTokenTTL < time.Minute {
+		return fmt.Errorf("refresh_token_ttl field: too short period")
+	}
+
+	if config.PasswordResetTTL < time.Minute {
+		return fmt.Errorf("password_reset_ttl field: too short period")
+	}
+
+	if config.EmailVerificationTTL < time.Minute {
+		return fmt.Errorf("email_verification_ttl field: too short period")
+	}
+
+	if err := config.LoginPolicy.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.PasswordPolicy.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.LoginThrottle.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.TwoFactor.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.Webhook.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.EventRelay.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.Email.Validate(); err != nil {
+		return err
+	}
+
+	if err := config.Inbox.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate performs a basic validation.
+func (config InboxConfig) Validate() error {
+	if config.Interval < 100*time.Millisecond {
+		return fmt.Errorf("inbox_interval field: too short period")
+	}
+
+	if config.Timeout < time.Second {
+		return fmt.Errorf("inbox_timeout field: too short period")
+	}
+
+	if config.BatchSize < 1 || config.BatchSize > 1000 {
+		return fmt.Errorf("inbox_batch_size field: must be in range [1, 1000]")
+	}
+
+	if config.Retention < time.Hour {
+		return fmt.Errorf("inbox_retention field: too short period")
+	}
+
+	if config.CleanupInterval < time.Minute {
+		return fmt.Errorf("inbox_cleanup_interval field: too short period")
+	}
+
+	return nil
+}
+
+// Validate performs a basic validation.
+func (config EmailConfig) Validate() error {
+	if config.DispatchInterval < time.Second {
+		return fmt.Errorf("email_dispatch_interval field: too short period")
+	}
+
+	if config.DispatchTimeout < time.Second {
+		return fmt.Errorf("email_dispatch_timeout field: too short period")
+	}
+
+	if config.BatchSize < 1 || config.BatchSize > 1000 {
+		return fmt.Errorf("email_batch_size field: must be in range [1, 1000]")
+	}
+
+	if config.MaxAttempts < 1 {
+		return fmt.Errorf("email_max_attempts field: must be positive")
+	}
+
+	if config.RetryBase <= 0 || config.RetryMax < config.RetryBase {
+		return fmt.Errorf("email_retry_base, email_retry_max fields: must be positive and ordered")
+	}
+
+	return nil
+}
+
+// retryDelay returns delay before the next attempt of email failed given number of times.
+func (config EmailConfig) retryDelay(attempts int) time.Duration {
+	return backoffDelay(config.RetryBase, config.RetryMax, attempts)
+}
+
+// Validate performs a basic validation.
+func (config EventRelayConfig) Validate() error {
+	if config.Interval < 100*time.Millisecond {
+		return fmt.Errorf("event_relay_interval field: too short period")
+	}
+
+	if config.Timeout < time.Second {
+		return fmt.Errorf("event_relay_timeout field: too short period")
+	}
+
+	if config.BatchSize < 1 || config.BatchSize > 1000 {
+		return fmt.Errorf("event_relay_batch_size field: must be in range [1, 1000]")
+	}
+
+	if config.MaxAttempts < 1 {
+		return fmt.Errorf("event_relay_max_attempts field: must be positive")
+	}
+
+	return nil
+}
+
+// Validate performs a basic validation.
+func (config WebhookConfig) Validate() error {
+	if config.DispatchInterval < time.Second {
+		return fmt.Errorf("webhook_dispatch_interval field: too short period")
+	}
+
+	if config.DispatchTimeout < time.Second {
+		return fmt.Errorf("webhook_dispatch_timeout field: too short period")
+	}
+
+	if config.BatchSize < 1 || config.BatchSize > 1000 {
+		return fmt.Errorf("webhook_batch_size field: must be in range [1, 1000]")
+	}
+
+	if config.MaxAttempts < 1 {
+		return fmt.Errorf("webhook_max_attempts field: must be positive")
+	}
+
+	if config.RetryBase <= 0 || config.RetryMax < config.RetryBase {
+		return fmt.Errorf("webhook_retry_base, webhook_retry_max fields: must be positive and ordered")
+	}
+
+	names := make(map[string]bool, len(config.Subscriptions))
+	for idx, subscription := range config.Subscriptions {
+		if err := subscription.Validate(); err != nil {
+			return fmt.Errorf("webhooks field [%d]: %w", idx, err)
+		}
+		if names[subscription.Name] {
+			return fmt.Errorf("webhooks field [%d]: duplicate name %s", idx, subscription.Name)
+		}
+		names[subscription.Name] = true
+	}
+
+	return nil
+}
+
+// Validate performs a basic validation.
+func (config WebhookSubscription) Validate() error {
+	if config.Name == "" || len(config.Name) > 64 {
+		return fmt.Errorf("name field: must be non-empty and at most 64 characters long")
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url field: must be absolute http(s) URL")
+	}
+
+	if config.Secret == "" {
+		return fmt.Errorf("secret field: empty")
+	}
+
+	if len(config.Events) == 0 {
+		return fmt.Errorf("events field: empty")
+	}
+	for _, event := range config.Events {
+		if err = event.Validate(); err != nil {
+			return fmt.Errorf("events field: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// retryDelay returns delay before the next attempt of delivery failed given number of times.
+func (config WebhookConfig) retryDelay(attempts int) time.Duration {
+	return backoffDelay(config.RetryBase, config.RetryMax, attempts)
+}
+
+// backoffDelay returns base delay doubled for each attempt after the first one, limited by max.
+func backoffDelay(base, max time.Duration, attempts int) time.Duration {
+	delay := base << (attempts - 1)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// Validate performs a basic validation.
+func (config TwoFactorConfig) Validate() error {
+	if config.Issuer == "" || strings.Contains(config.Issuer, ":") {
+		return fmt.Errorf("totp_issuer field: must be non-empty and must not contain colon")
+	}
+
+	if config.Skew < 0 || config.Skew > 3 {
+		return fmt.Errorf("totp_skew field: must be in range [0, 3]")
+	}
+
+	if config.RecoveryCodes < 1 || config.RecoveryCodes > 20 {
+		return fmt.Errorf("totp_recovery_codes field: must be in range [1, 20]")
+	}
+
+	if config.EncryptionKey == "" {
+		return fmt.Errorf("totp_encryption_key field: empty")
+	}
+
+	return nil
+}
+
+// Validate performs a basic validation.
+func (config LoginThrottleConfig) Validate() error {
+	if !config.Enabled {
+		return nil
+	}
+
+	if config.Window < time.Second {
+		return fmt.Errorf("login_throttle_window field: too short period")
+	}
+
+	if config.DelayThreshold < 1 {
+		return fmt.Errorf("login_throttle_delay_threshold field: must be positive")
+	}
+
+	if config.DelayBase <= 0 || config.DelayMax < config.DelayBase {
+		return fmt.Errorf("login_throttle_delay_base, login_throttle_delay_max fields: must be positive and ordered")
+	}
+
+	if config.LockoutThreshold <= config.DelayThreshold {
+		return fmt.Errorf("login_throttle_lockout_threshold field: must be greater than login_throttle_delay_threshold")
+	}
+
+	if config.IPLockoutThreshold < 1 {
+		return fmt.Errorf("login_throttle_ip_lockout_threshold field: must be positive")
+	}
+
+	if config.LockoutDuration < time.Second {
+		return fmt.Errorf("login_throttle_lockout_duration field: too short period")
+	}
+
 	return nil
 }
 
 // NewDefaultConfig builds a Config with default values.
 func NewDefaultConfig() Config {
 	return Config{
-		UpdaterTimeout:      5 * time.Second,
-		StatusCheckInterval: 5 * time.Second,
+		UpdaterTimeout:       5 * time.Second,
+		StatusCheckInterval:  5 * time.Second,
+		RefreshTokenTTL:      30 * 24 * time.Hour,
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: 24 * time.Hour,
+		LoginPolicy:          validator.NewDefaultLoginPolicy(),
+		PasswordPolicy:       validator.NewDefaultPasswordPolicy(),
+		LoginThrottle: LoginThrottleConfig{
+			Enabled:            true,
+			Window:             15 * time.Minute,
+			DelayThreshold:     3,
+			DelayBase:          time.Second,
+			DelayMax:           time.Minute,
+			LockoutThreshold:   10,
+			IPLockoutThreshold: 100,
+			LockoutDuration:    15 * time.Minute,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:        "Gophermart",
+			Skew:          1,
+			RecoveryCodes: 10,
+			EncryptionKey: "totp_encryption_key",
+		},
+		Webhook: WebhookConfig{
+			DispatchInterval: 5 * time.Second,
+			DispatchTimeout:  time.Minute,
+			BatchSize:        100,
+			MaxAttempts:      10,
+			RetryBase:        30 * time.Second,
+			RetryMax:         6 * time.Hour,
+		},
+		EventRelay: EventRelayConfig{
+			Interval:    time.Second,
+			Timeout:     30 * time.Second,
+			BatchSize:   100,
+			MaxAttempts: 100,
+		},
+		Email: EmailConfig{
+			DispatchInterval: 5 * time.Second,
+			DispatchTimeout:  time.Minute,
+			BatchSize:        100,
+			MaxAttempts:      5,
+			RetryBase:        time.Minute,
+			RetryMax:         time.Hour,
+		},
+		Inbox: InboxConfig{
+			Interval:        time.Second,
+			Timeout:         30 * time.Second,
+			BatchSize:       100,
+			Retention:       90 * 24 * time.Hour,
+			CleanupInterval: time.Hour,
+		},
 	}
 }
@@ -0,0 +1,79 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const (
+	emailVerificationSubject = "Email verification"
+	emailVerificationBody    = "Use the following token to verify your email address: %s\n" +
+		"The token expires at %s. If you didn't change your email, ignore this message."
+)
+
+// VerifyEmail sets user email to the one email verification token is sent to.
+func (svc *Service) VerifyEmail(ctx context.Context, userID uuid.UUID, token string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.VerifyEmail")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if token == "" {
+		return pkg.ErrInvalidToken
+	}
+
+	if err := svc.storage.VerifyEmail(ctx, userID, token); err != nil {
+		return fmt.Errorf("verifying email: %w", err)
+	}
+
+	return nil
+}
+
+// changeEmail checks user password and clears user email if the new one is empty.
+// Otherwise, it sends email verification token to the new email, the current one is kept till the token is used,
+// so password reset tokens are never sent to an email the user doesn't own.
+func (svc *Service) changeEmail(ctx context.Context, userID uuid.UUID, email, password string) error {
+	if password == "" {
+		return pkg.ErrWrongCredentials
+	}
+
+	if email == "" {
+		if err := svc.storage.ClearEmail(ctx, userID, password); err != nil {
+			return fmt.Errorf("clearing email: %w", err)
+		}
+		return nil
+	}
+
+	token, err := pkg.NewRandomToken()
+	if err != nil {
+		return err
+	}
+
+	obj, err := svc.storage.CreateEmailVerificationToken(ctx, password, model.EmailVerificationToken{
+		UserID:    userID,
+		Email:     email,
+		Token:     token,
+		ExpiresAt: time.Now().Add(svc.config.EmailVerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("creating email verification token: %w", err)
+	}
+
+	msg := model.Message{
+		To:      obj.Email,
+		Subject: emailVerificationSubject,
+		Body:    fmt.Sprintf(emailVerificationBody, obj.Token, obj.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	if err = svc.notifier.Send(ctx, msg); err != nil {
+		return fmt.Errorf("sending email verification token: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage"
+)
+
+// fakeStorage implements storage.Storage methods used by email related service methods.
+// Calling any other method panics on the nil embedded interface.
+type fakeStorage struct {
+	storage.Storage
+	user              model.User
+	verificationToken model.EmailVerificationToken
+	emailCleared      bool
+}
+
+func (st *fakeStorage) GetUserByLogin(_ context.Context, _ string) (model.User, error) {
+	return st.user, nil
+}
+
+func (st *fakeStorage) CreatePasswordResetToken(_ context.Context, obj model.PasswordResetToken) (model.PasswordResetToken, error) {
+	return obj, nil
+}
+
+func (st *fakeStorage) GetNotificationSettings(_ context.Context, _ uuid.UUID) (model.NotificationSettings, error) {
+	return model.NotificationSettings{
+		Email:         st.user.Email,
+		EmailVerified: !st.user.EmailVerifiedAt.IsZero(),
+		EmailEnabled:  map[model.NotificationType]bool{},
+	}, nil
+}
+
+func (st *fakeStorage) SetNotificationSettings(_ context.Context, _ uuid.UUID, _ model.NotificationSettings) error {
+	return nil
+}
+
+func (st *fakeStorage) CreateEmailVerificationToken(_ context.Context, password string, obj model.EmailVerificationToken) (model.EmailVerificationToken, error) {
+	if password != "password" {
+		return model.EmailVerificationToken{}, pkg.ErrWrongCredentials
+	}
+	st.verificationToken = obj
+
+	return obj, nil
+}
+
+func (st *fakeStorage) ClearEmail(_ context.Context, _ uuid.UUID, password string) error {
+	if password != "password" {
+		return pkg.ErrWrongCredentials
+	}
+	st.emailCleared = true
+
+	return nil
+}
+
+// fakeNotifier keeps sent messages.
+type fakeNotifier struct {
+	sent []model.Message
+}
+
+func (n *fakeNotifier) Send(_ context.Context, msg model.Message) error {
+	n.sent = append(n.sent, msg)
+
+	return nil
+}
+
+func TestServiceSendPasswordResetTokenRecipient(t *testing.T) {
+	testCases := []struct {
+		name   string
+		user   model.User
+		wantTo string
+	}{
+		{
+			name:   "no email",
+			user:   model.User{Login: "apricot"},
+			wantTo: "apricot",
+		},
+		{
+			name:   "unverified email",
+			user:   model.User{Login: "apricot", Email: "attacker@example.com"},
+			wantTo: "apricot",
+		},
+		{
+			name:   "verified email",
+			user:   model.User{Login: "apricot", Email: "apricot@example.com", EmailVerifiedAt: time.Now()},
+			wantTo: "apricot@example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.user.ID = uuid.New()
+			notifier := &fakeNotifier{}
+			svc := &Service{config: NewDefaultConfig(), storage: &fakeStorage{user: tc.user}, notifier: notifier}
+
+			if err := svc.sendPasswordResetToken(context.Background(), tc.user); err != nil {
+				t.Fatalf("sendPasswordResetToken: %v", err)
+			}
+			if len(notifier.sent) != 1 || notifier.sent[0].To != tc.wantTo {
+				t.Errorf("sent: got %+v, want one message to %s", notifier.sent, tc.wantTo)
+			}
+		})
+	}
+}
+
+func TestServiceSetNotificationSettingsEmail(t *testing.T) {
+	verifiedUser := model.User{ID: uuid.New(), Email: "apricot@example.com", EmailVerifiedAt: time.Now()}
+
+	testCases := []struct {
+		name        string
+		email       string
+		password    string
+		wantErr     error
+		wantSentTo  string
+		wantCleared bool
+	}{
+		{name: "unchanged", email: "apricot@example.com"},
+		{name: "no password", email: "new@example.com", wantErr: pkg.ErrWrongCredentials},
+		{name: "wrong password", email: "new@example.com", password: "wrong", wantErr: pkg.ErrWrongCredentials},
+		{name: "changed", email: " new@example.com ", password: "password", wantSentTo: "new@example.com"},
+		{name: "cleared without password", email: "", wantErr: pkg.ErrWrongCredentials},
+		{name: "cleared", email: "", password: "password", wantCleared: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			st := &fakeStorage{user: verifiedUser}
+			notifier := &fakeNotifier{}
+			svc := &Service{config: NewDefaultConfig(), storage: st, notifier: notifier}
+
+			settings := model.NotificationSettings{Email: tc.email}
+			_, err := svc.SetNotificationSettings(context.Background(), verifiedUser.ID, settings, tc.password)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("SetNotificationSettings: got error %v, want %v", err, tc.wantErr)
+			}
+
+			if tc.wantSentTo == "" {
+				if len(notifier.sent) != 0 {
+					t.Errorf("sent: got %+v, want none", notifier.sent)
+				}
+			} else {
+				if len(notifier.sent) != 1 || notifier.sent[0].To != tc.wantSentTo {
+					t.Fatalf("sent: got %+v, want one message to %s", notifier.sent, tc.wantSentTo)
+				}
+				if st.verificationToken.Email != tc.wantSentTo || st.verificationToken.Token == "" {
+					t.Errorf("verification token: got %+v", st.verificationToken)
+				}
+			}
+			if st.emailCleared != tc.wantCleared {
+				t.Errorf("email cleared: got %v, want %v", st.emailCleared, tc.wantCleared)
+			}
+		})
+	}
+}
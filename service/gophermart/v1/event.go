@@ -0,0 +1,96 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+// eventRelay publishes outbox events to the event publisher in the order they were written.
+// Publishing stops at the first failure, so the failed event and the following ones are retried the next tick
+// until the failed one becomes dead after MaxAttempts attempts.
+func (svc *Service) eventRelay(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "eventRelay").Logger()
+	config := svc.config.EventRelay
+
+	relay := func() (err error) {
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "eventRelay.relay")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		tickCtx, cancel := context.WithTimeout(tickCtx, config.Timeout)
+		defer cancel()
+
+		objs, err := svc.storage.ClaimUnpublishedEvents(tickCtx, config.BatchSize, config.Timeout)
+		if err != nil {
+			return fmt.Errorf("claim unpublished events: %w", err)
+		}
+		if len(objs) == 0 {
+			return nil
+		}
+
+		published := make([]uuid.UUID, 0, len(objs))
+		var publishErr error
+		for _, obj := range objs {
+			if publishErr = svc.publisher.Publish(tickCtx, obj); publishErr != nil {
+				break
+			}
+			published = append(published, obj.ID)
+		}
+		metrics.EventsPublishedTotal.Add(float64(len(published)))
+
+		if len(published) > 0 {
+			if err = svc.storage.MarkEventsPublished(tickCtx, published); err != nil {
+				return fmt.Errorf("mark events published: %w", err)
+			}
+		}
+
+		if publishErr == nil {
+			return nil
+		}
+
+		failed := objs[len(published)]
+		dead, err := svc.storage.FailEventPublishing(tickCtx, failed.ID, publishErr.Error(), config.MaxAttempts)
+		if err != nil {
+			return fmt.Errorf("fail event publishing: %w", err)
+		}
+		if dead {
+			metrics.EventsDeadTotal.Inc()
+		}
+
+		// Events following the failed one are released to keep the order
+		if rest := objs[len(published)+1:]; len(rest) > 0 {
+			ids := make([]uuid.UUID, 0, len(rest))
+			for _, obj := range rest {
+				ids = append(ids, obj.ID)
+			}
+			if err = svc.storage.ReleaseEvents(tickCtx, ids); err != nil {
+				return fmt.Errorf("release events: %w", err)
+			}
+		}
+
+		return fmt.Errorf("publish event %s: %w", failed.ID, publishErr)
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("eventRelay closed")
+			return
+		case <-ticker.C:
+			if err := relay(); err != nil {
+				logger.Warn().Err(err).Msg("eventRelay:")
+			}
+		}
+	}
+}
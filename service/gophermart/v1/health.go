@@ -0,0 +1,31 @@
+package gophermart
+
+import (
+	"context"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const (
+	healthCheckStorage    = "storage"
+	healthCheckMigrations = "migrations"
+	healthCheckAccrual    = "accrual"
+)
+
+// CheckReadiness checks service dependencies health.
+func (svc *Service) CheckReadiness(ctx context.Context) []model.HealthCheck {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.CheckReadiness")
+	defer span.End()
+
+	checks := []model.HealthCheck{
+		{Name: healthCheckStorage, Err: svc.storage.Ping(ctx)},
+		{Name: healthCheckMigrations, Err: svc.storage.CheckMigrations(ctx)},
+	}
+
+	if svc.config.ReadinessCheckAccrual {
+		checks = append(checks, model.HealthCheck{Name: healthCheckAccrual, Err: svc.provider.Ping(ctx)})
+	}
+
+	return checks
+}
@@ -0,0 +1,229 @@
+package gophermart
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+	// maxMarkReadIDs is the largest number of notifications marked read by IDs at once.
+	maxMarkReadIDs = 100
+)
+
+// pointsAdjustedData is points.adjusted inbox notification data:
+// adjustment reason is written by operators for internal use, so it is not shown to users.
+type pointsAdjustedData struct {
+	AdjustmentID uuid.UUID `json:"adjustment_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Amount       float32   `json:"amount"`
+	Balance      float32   `json:"balance"`
+}
+
+// GetNotifications gets user inbox notifications (unread ones only if unreadOnly is set), latest first,
+// along with user notification counters. Zero limit means the default one.
+func (svc *Service) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (_ model.NotificationList, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetNotifications")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if limit == 0 {
+		limit = defaultNotificationsLimit
+	}
+	if limit < 0 || limit > maxNotificationsLimit || offset < 0 {
+		return model.NotificationList{}, fmt.Errorf("%w: limit must be within [1, %d], offset must not be negative", pkg.ErrInvalidInput, maxNotificationsLimit)
+	}
+
+	obj, err := svc.storage.GetNotifications(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return model.NotificationList{}, fmt.Errorf("getting notifications: %w", err)
+	}
+
+	return obj, nil
+}
+
+// MarkNotificationsRead marks given user inbox notifications (all if none given) read.
+// Returns the number of user unread notifications left.
+func (svc *Service) MarkNotificationsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (_ int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.MarkNotificationsRead")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if len(ids) > maxMarkReadIDs {
+		return 0, fmt.Errorf("%w: at most %d ids are allowed", pkg.ErrInvalidInput, maxMarkReadIDs)
+	}
+
+	unread, err := svc.storage.MarkNotificationsRead(ctx, userID, ids)
+	if err != nil {
+		return 0, fmt.Errorf("marking notifications read: %w", err)
+	}
+
+	return unread, nil
+}
+
+// inboxWriter turns outbox events of order status and balance changes into users inbox notifications.
+func (svc *Service) inboxWriter(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "inboxWriter").Logger()
+	config := svc.config.Inbox
+
+	write := func() (err error) {
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "inboxWriter.write")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		tickCtx, cancel := context.WithTimeout(tickCtx, config.Timeout)
+		defer cancel()
+
+		events, err := svc.storage.GetPendingInboxEvents(tickCtx, config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("get pending events: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		eventIDs := make([]uuid.UUID, 0, len(events))
+		var objs []model.Notification
+		for _, event := range events {
+			eventIDs = append(eventIDs, event.ID)
+
+			obj, ok, err := inboxNotificationOf(event)
+			if err != nil {
+				// Malformed event can't be notified of, it mustn't block the following ones though
+				logger.Warn().Err(err).Msgf("skipping event %s notification", event.ID)
+				continue
+			}
+			if ok {
+				objs = append(objs, obj)
+			}
+		}
+
+		if _, err = svc.storage.AddNotifications(tickCtx, eventIDs, objs); err != nil {
+			return fmt.Errorf("add notifications: %w", err)
+		}
+
+		return nil
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("inboxWriter closed")
+			return
+		case <-ticker.C:
+			if err := write(); err != nil {
+				logger.Warn().Err(err).Msg("inboxWriter:")
+			}
+		}
+	}
+}
+
+// inboxCleaner deletes inbox notifications older than the retention period.
+func (svc *Service) inboxCleaner(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "inboxCleaner").Logger()
+	config := svc.config.Inbox
+
+	cleanup := func() (err error) {
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "inboxCleaner.cleanup")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		tickCtx, cancel := context.WithTimeout(tickCtx, config.Timeout)
+		defer cancel()
+
+		if _, err = svc.storage.DeleteNotificationsBefore(tickCtx, time.Now().Add(-config.Retention)); err != nil {
+			return fmt.Errorf("delete notifications: %w", err)
+		}
+
+		return nil
+	}
+
+	ticker := time.NewTicker(config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("inboxCleaner closed")
+			return
+		case <-ticker.C:
+			if err := cleanup(); err != nil {
+				logger.Warn().Err(err).Msg("inboxCleaner:")
+			}
+		}
+	}
+}
+
+// inboxNotificationOf builds inbox notification of outbox event, event payload is kept as notification data
+// (except for internal fields).
+// False is returned for events users aren't notified of.
+func inboxNotificationOf(event model.Event) (model.Notification, bool, error) {
+	obj := model.Notification{
+		EventID:   event.ID,
+		Type:      event.Type,
+		Data:      event.Payload,
+		CreatedAt: event.CreatedAt,
+	}
+
+	switch event.Type {
+	case model.EventTypeOrderStatusChanged:
+		var payload model.OrderStatusChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return model.Notification{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		obj.UserID = payload.UserID
+		obj.Message = fmt.Sprintf("Order %s status changed from %s to %s", payload.Order, payload.PreviousStatus, payload.Status)
+	case model.EventTypePointsAccrued:
+		var payload model.PointsAccruedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return model.Notification{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		obj.UserID = payload.UserID
+		obj.Message = fmt.Sprintf("%.2f points accrued for order %s, balance is %.2f", payload.Amount, payload.Order, payload.Balance)
+	case model.EventTypePointsWithdrawn:
+		var payload model.PointsWithdrawnPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return model.Notification{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		obj.UserID = payload.UserID
+		obj.Message = fmt.Sprintf("%.2f points withdrawn for order %s, balance is %.2f", payload.Amount, payload.Order, payload.Balance)
+	case model.EventTypePointsAdjusted:
+		var payload model.PointsAdjustedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return model.Notification{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		data, err := json.Marshal(pointsAdjustedData{
+			AdjustmentID: payload.AdjustmentID,
+			UserID:       payload.UserID,
+			Amount:       payload.Amount,
+			Balance:      payload.Balance,
+		})
+		if err != nil {
+			return model.Notification{}, false, fmt.Errorf("encoding data: %w", err)
+		}
+		obj.UserID = payload.UserID
+		obj.Data = data
+		obj.Message = fmt.Sprintf("Balance adjusted by %+.2f points, balance is %.2f", payload.Amount, payload.Balance)
+	default:
+		return model.Notification{}, false, nil
+	}
+
+	return obj, true, nil
+}
@@ -0,0 +1,51 @@
+package gophermart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+)
+
+func TestInboxNotificationOfPointsAdjusted(t *testing.T) {
+	const reason = "Internal: duplicate accrual, ticket 42"
+
+	payload, err := json.Marshal(model.PointsAdjustedPayload{
+		AdjustmentID: uuid.New(),
+		UserID:       uuid.New(),
+		Amount:       -50,
+		Balance:      701,
+		Reason:       reason,
+	})
+	if err != nil {
+		t.Fatalf("encode payload: %v", err)
+	}
+
+	obj, ok, err := inboxNotificationOf(model.Event{ID: uuid.New(), Type: model.EventTypePointsAdjusted, Payload: payload})
+	if err != nil || !ok {
+		t.Fatalf("inboxNotificationOf: got %v, %v", ok, err)
+	}
+
+	if want := "Balance adjusted by -50.00 points, balance is 701.00"; obj.Message != want {
+		t.Errorf("message: got %q, want %q", obj.Message, want)
+	}
+	if strings.Contains(string(obj.Data), "reason") || strings.Contains(string(obj.Data), reason) {
+		t.Errorf("data contains reason: %s", obj.Data)
+	}
+}
+
+func TestServiceMarkNotificationsReadTooManyIDs(t *testing.T) {
+	svc := &Service{}
+	ids := make([]uuid.UUID, maxMarkReadIDs+1)
+
+	_, err := svc.MarkNotificationsRead(context.Background(), uuid.New(), ids)
+	if !errors.Is(err, pkg.ErrInvalidInput) {
+		t.Errorf("MarkNotificationsRead: got error %v, want %v", err, pkg.ErrInvalidInput)
+	}
+}
@@ -0,0 +1,131 @@
+package gophermart
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+// loginAttemptsSubjectLength is a length of login_attempts.subject column.
+const loginAttemptsSubjectLength = 100
+
+// UnlockLogin resets failed login attempts and lockout for given login.
+func (svc *Service) UnlockLogin(ctx context.Context, login string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.UnlockLogin")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	user, err := svc.storage.GetUserByLogin(ctx, validator.NormalizeLogin(login))
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	if err = svc.storage.DeleteLoginAttempts(ctx, loginAttemptsKey(user.Login)); err != nil {
+		return fmt.Errorf("deleting login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// acquireLoginAttempts counts login attempt as failed in advance by given keys,
+// an error is returned if login or client IP is locked out.
+// Attempt must be released or reset on success, so concurrent attempts can't exceed the thresholds.
+func (svc *Service) acquireLoginAttempts(ctx context.Context, keys []model.LoginAttemptsKey) error {
+	logger := svc.Logger(ctx)
+	config := svc.config.LoginThrottle
+
+	for _, key := range keys {
+		rule := config.rule(key.Kind)
+
+		obj, err := svc.storage.AcquireLoginAttempt(ctx, key, rule)
+		if err != nil {
+			if !errors.Is(err, pkg.ErrTooManyAttempts) {
+				return fmt.Errorf("acquiring login attempt: %w", err)
+			}
+
+			lockErr := pkg.ErrTooManyAttempts
+			if obj.Kind == model.LoginAttemptsByLogin && obj.Failures >= rule.LockoutThreshold {
+				lockErr = pkg.ErrAccountLocked
+			}
+
+			return pkg.NewRetryAfterError(lockErr, obj.LockedUntil)
+		}
+
+		if obj.Failures == rule.LockoutThreshold {
+			logger.Warn().Msgf("login attempts locked out for %s %s after %d failures", key.Kind, key.Subject, obj.Failures)
+		}
+	}
+
+	return nil
+}
+
+// releaseLoginAttempts takes back login attempt acquired by given keys.
+// With reset login failures are reset, client IP failures aren't,
+// so valid credentials of one account don't unlock guessing others.
+func (svc *Service) releaseLoginAttempts(ctx context.Context, keys []model.LoginAttemptsKey, reset bool) {
+	logger := svc.Logger(ctx)
+
+	for _, key := range keys {
+		var err error
+		if reset && key.Kind == model.LoginAttemptsByLogin {
+			err = svc.storage.DeleteLoginAttempts(ctx, key)
+		} else {
+			err = svc.storage.ReleaseLoginAttempt(ctx, key, svc.config.LoginThrottle.rule(key.Kind))
+		}
+		if err != nil {
+			logger.Error().Err(err).Msgf("releasing login attempt for %s", key.Kind)
+		}
+	}
+}
+
+// rule returns login attempts locking rule for given kind of attempts.
+func (config LoginThrottleConfig) rule(kind model.LoginAttemptsKind) model.LoginAttemptsRule {
+	if kind == model.LoginAttemptsByIP {
+		return model.LoginAttemptsRule{
+			Window:           config.Window,
+			LockoutThreshold: config.IPLockoutThreshold,
+			LockoutDuration:  config.LockoutDuration,
+		}
+	}
+
+	return model.LoginAttemptsRule{
+		Window:           config.Window,
+		DelayThreshold:   config.DelayThreshold,
+		DelayBase:        config.DelayBase,
+		DelayMax:         config.DelayMax,
+		LockoutThreshold: config.LockoutThreshold,
+		LockoutDuration:  config.LockoutDuration,
+	}
+}
+
+// loginAttemptsKeys returns keys failed login attempts are tracked by.
+// Client IP goes first, so attempts of locked out client IP aren't counted against the login.
+func loginAttemptsKeys(login string, client model.ClientInfo) []model.LoginAttemptsKey {
+	var keys []model.LoginAttemptsKey
+	if client.IP != "" {
+		keys = append(keys, model.LoginAttemptsKey{Kind: model.LoginAttemptsByIP, Subject: client.IP})
+	}
+
+	return append(keys, loginAttemptsKey(login))
+}
+
+// loginAttemptsKey returns key failed login attempts are tracked by for given normalized login.
+// Logins aren't length limited on authentication, so long ones are hashed to fit the column.
+func loginAttemptsKey(login string) model.LoginAttemptsKey {
+	subject := strings.ToLower(login)
+	if len(subject) > loginAttemptsSubjectLength {
+		hash := sha256.Sum256([]byte(subject))
+		subject = "sha256:" + hex.EncodeToString(hash[:])
+	}
+
+	return model.LoginAttemptsKey{Kind: model.LoginAttemptsByLogin, Subject: subject}
+}
@@ -0,0 +1,71 @@
+package gophermart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+func TestLoginAttemptsKey(t *testing.T) {
+	longLogin := strings.Repeat("a", loginAttemptsSubjectLength+1)
+
+	testCases := []struct {
+		name        string
+		login       string
+		wantSubject string
+	}{
+		{name: "lower-cased", login: "Apricot", wantSubject: "apricot"},
+		{name: "column length", login: longLogin[1:], wantSubject: longLogin[1:]},
+		{name: "hashed", login: longLogin, wantSubject: "sha256:9d0793397991b57a99a07c6e6b4a92bab68dbf605345cd0b87f385a448a726bc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := loginAttemptsKey(tc.login)
+			if key.Kind != model.LoginAttemptsByLogin {
+				t.Errorf("kind: got %s, want %s", key.Kind, model.LoginAttemptsByLogin)
+			}
+			if len(key.Subject) > loginAttemptsSubjectLength {
+				t.Errorf("subject length: got %d, want at most %d", len(key.Subject), loginAttemptsSubjectLength)
+			}
+			if key.Subject != tc.wantSubject {
+				t.Errorf("subject: got %s, want %s", key.Subject, tc.wantSubject)
+			}
+		})
+	}
+
+	if loginAttemptsKey(longLogin) == loginAttemptsKey(longLogin+"a") {
+		t.Error("different long logins have the same key")
+	}
+	if loginAttemptsKey(strings.ToUpper(longLogin)) != loginAttemptsKey(longLogin) {
+		t.Error("long logins differing in case have different keys")
+	}
+}
+
+func TestLoginAttemptsKeys(t *testing.T) {
+	keys := loginAttemptsKeys("apricot", model.ClientInfo{IP: "192.0.2.1"})
+	if len(keys) != 2 || keys[0].Kind != model.LoginAttemptsByIP || keys[1].Kind != model.LoginAttemptsByLogin {
+		t.Fatalf("keys: got %+v, want client IP and login ones", keys)
+	}
+
+	keys = loginAttemptsKeys("apricot", model.ClientInfo{})
+	if len(keys) != 1 || keys[0].Kind != model.LoginAttemptsByLogin {
+		t.Fatalf("keys: got %+v, want login one", keys)
+	}
+}
+
+func TestLoginThrottleConfigRule(t *testing.T) {
+	config := NewDefaultConfig().LoginThrottle
+
+	loginRule := config.rule(model.LoginAttemptsByLogin)
+	if loginRule.DelayThreshold != config.DelayThreshold || loginRule.LockoutThreshold != config.LockoutThreshold {
+		t.Errorf("login rule: got %+v", loginRule)
+	}
+
+	// Client IP attempts are locked out only, never delayed
+	ipRule := config.rule(model.LoginAttemptsByIP)
+	if ipRule.DelayThreshold != 0 || ipRule.LockoutThreshold != config.IPLockoutThreshold {
+		t.Errorf("client IP rule: got %+v", ipRule)
+	}
+}
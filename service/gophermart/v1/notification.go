@@ -0,0 +1,299 @@
+package gophermart
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+type (
+	// emailData keeps email templates data.
+	emailData struct {
+		Order   string
+		Amount  float32
+		Balance float32
+	}
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// emailTemplates keeps email templates by notification type, each defines "subject" and "body" templates.
+var emailTemplates = mustParseEmailTemplates()
+
+// mustParseEmailTemplates parses embedded email templates, panics if any notification type template is broken.
+func mustParseEmailTemplates() map[model.NotificationType]*template.Template {
+	tmpls := make(map[model.NotificationType]*template.Template, len(model.NotificationTypes))
+	for _, notificationType := range model.NotificationTypes {
+		tmpls[notificationType] = template.Must(template.ParseFS(templatesFS, "templates/"+string(notificationType)+".tmpl"))
+	}
+
+	return tmpls
+}
+
+// GetNotificationSettings gets user notification settings, notification types not opted in are disabled.
+func (svc *Service) GetNotificationSettings(ctx context.Context, userID uuid.UUID) (_ model.NotificationSettings, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetNotificationSettings")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	obj, err := svc.storage.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return model.NotificationSettings{}, fmt.Errorf("getting notification settings: %w", err)
+	}
+
+	for _, notificationType := range model.NotificationTypes {
+		if _, ok := obj.EmailEnabled[notificationType]; !ok {
+			obj.EmailEnabled[notificationType] = false
+		}
+	}
+
+	return obj, nil
+}
+
+// SetNotificationSettings sets opt-in preferences of given notification types and changes user email
+// if it differs from the current one. Email change requires the current password:
+// a new email is set once verified (see changeEmail), an empty one clears the email at once.
+func (svc *Service) SetNotificationSettings(ctx context.Context, userID uuid.UUID, obj model.NotificationSettings, password string) (_ model.NotificationSettings, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.SetNotificationSettings")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	obj.Email = validator.NormalizeEmail(obj.Email)
+	if errs := validator.ValidateNotificationSettings(obj); len(errs) > 0 {
+		return model.NotificationSettings{}, errs
+	}
+
+	current, err := svc.storage.GetNotificationSettings(ctx, userID)
+	if err != nil {
+		return model.NotificationSettings{}, fmt.Errorf("getting notification settings: %w", err)
+	}
+	if obj.Email != current.Email {
+		if err = svc.changeEmail(ctx, userID, obj.Email, password); err != nil {
+			return model.NotificationSettings{}, err
+		}
+	}
+
+	if err = svc.storage.SetNotificationSettings(ctx, userID, obj); err != nil {
+		return model.NotificationSettings{}, fmt.Errorf("setting notification settings: %w", err)
+	}
+
+	return svc.GetNotificationSettings(ctx, userID)
+}
+
+// emailDispatcher turns outbox events into email notifications of opted in users and sends them.
+// It runs independently of orderStatusUpdater, so slow or unavailable mail server never delays order processing.
+func (svc *Service) emailDispatcher(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "emailDispatcher").Logger()
+	config := svc.config.Email
+
+	dispatch := func() (err error) {
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "emailDispatcher.dispatch")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		// Claimed emails are leased for the tick timeout, so they're retried if the tick is interrupted
+		tickCtx, cancel := context.WithTimeout(tickCtx, config.DispatchTimeout)
+		defer cancel()
+
+		if err = svc.enqueueEmails(tickCtx); err != nil {
+			return fmt.Errorf("enqueue emails: %w", err)
+		}
+
+		objs, err := svc.storage.ClaimEmails(tickCtx, config.BatchSize, config.DispatchTimeout)
+		if err != nil {
+			return fmt.Errorf("claim emails: %w", err)
+		}
+
+		for _, obj := range objs {
+			if err = svc.sendEmail(tickCtx, obj); err != nil {
+				return fmt.Errorf("email %s: %w", obj.ID, err)
+			}
+		}
+
+		return nil
+	}
+
+	ticker := time.NewTicker(config.DispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("emailDispatcher closed")
+			return
+		case <-ticker.C:
+			if err := dispatch(); err != nil {
+				logger.Warn().Err(err).Msg("emailDispatcher:")
+			}
+		}
+	}
+}
+
+// enqueueEmails renders emails of pending outbox events for users opted in to their notification type.
+// Email address is taken at the moment of rendering, so later changes don't affect queued emails.
+func (svc *Service) enqueueEmails(ctx context.Context) error {
+	events, err := svc.storage.GetPendingNotificationEvents(ctx, svc.config.Email.BatchSize)
+	if err != nil {
+		return fmt.Errorf("get pending events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	settings := make(map[uuid.UUID]model.NotificationSettings)
+	eventIDs := make([]uuid.UUID, 0, len(events))
+	var objs []model.Email
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+
+		userID, notificationType, data, ok, err := notificationOf(event)
+		if err != nil {
+			// Malformed event can't be notified of, it mustn't block the following ones though
+			logger := svc.Logger(ctx)
+			logger.Warn().Err(err).Msgf("skipping event %s notification", event.ID)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		userSettings, cached := settings[userID]
+		if !cached {
+			userSettings, err = svc.storage.GetNotificationSettings(ctx, userID)
+			if err != nil && !errors.Is(err, pkg.ErrNotFound) {
+				return fmt.Errorf("get user %s notification settings: %w", userID, err)
+			}
+			settings[userID] = userSettings
+		}
+		if userSettings.Email == "" || !userSettings.EmailEnabled[notificationType] {
+			continue
+		}
+
+		msg, err := renderEmail(notificationType, data)
+		if err != nil {
+			return fmt.Errorf("render %s email: %w", notificationType, err)
+		}
+		msg.To = userSettings.Email
+
+		objs = append(objs, model.Email{
+			EventID: event.ID,
+			UserID:  userID,
+			Type:    notificationType,
+			Message: msg,
+		})
+	}
+
+	if _, err = svc.storage.EnqueueEmails(ctx, eventIDs, objs); err != nil {
+		return fmt.Errorf("save emails: %w", err)
+	}
+
+	return nil
+}
+
+// notificationOf returns user and notification type the outbox event notifies of along with the template data,
+// false is returned for events users aren't notified of.
+func notificationOf(event model.Event) (uuid.UUID, model.NotificationType, emailData, bool, error) {
+	switch event.Type {
+	case model.EventTypePointsAccrued:
+		var payload model.PointsAccruedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return uuid.Nil, "", emailData{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		data := emailData{Order: payload.Order, Amount: payload.Amount, Balance: payload.Balance}
+
+		return payload.UserID, model.NotificationAccrualProcessed, data, true, nil
+	case model.EventTypePointsWithdrawn:
+		var payload model.PointsWithdrawnPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return uuid.Nil, "", emailData{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		data := emailData{Order: payload.Order, Amount: payload.Amount, Balance: payload.Balance}
+
+		return payload.UserID, model.NotificationWithdrawalMade, data, true, nil
+	case model.EventTypeOrderStatusChanged:
+		var payload model.OrderStatusChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return uuid.Nil, "", emailData{}, false, fmt.Errorf("decoding payload: %w", err)
+		}
+		if model.OrderStatus(payload.Status) != model.OrderStatusInvalid {
+			return uuid.Nil, "", emailData{}, false, nil
+		}
+
+		return payload.UserID, model.NotificationOrderInvalid, emailData{Order: payload.Order}, true, nil
+	default:
+		return uuid.Nil, "", emailData{}, false, nil
+	}
+}
+
+// renderEmail renders subject and body of notification type email.
+func renderEmail(notificationType model.NotificationType, data emailData) (model.Message, error) {
+	tmpl, ok := emailTemplates[notificationType]
+	if !ok {
+		return model.Message{}, fmt.Errorf("no template")
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return model.Message{}, err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return model.Message{}, err
+	}
+
+	return model.Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    body.String(),
+	}, nil
+}
+
+// sendEmail makes email sending attempt and saves its result.
+func (svc *Service) sendEmail(ctx context.Context, obj model.Email) error {
+	config := svc.config.Email
+
+	err := svc.notifier.Send(ctx, obj.Message)
+	if ctx.Err() != nil {
+		// The attempt is interrupted by the tick timeout, email is retried after the lease
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	obj.Attempts++
+	obj.NextAttemptAt = now
+	switch {
+	case err == nil:
+		obj.Status = model.EmailSent
+		obj.SentAt = now
+		obj.LastError = ""
+	case obj.Attempts >= config.MaxAttempts:
+		obj.Status = model.EmailFailed
+		obj.LastError = truncateError(err)
+		logger := svc.Logger(ctx)
+		logger.Warn().Err(err).Msgf("email %s failed after %d attempts", obj.ID, obj.Attempts)
+	default:
+		obj.Status = model.EmailPending
+		obj.NextAttemptAt = now.Add(config.retryDelay(obj.Attempts))
+		obj.LastError = truncateError(err)
+	}
+	metrics.EmailsSentTotal.WithLabelValues(string(obj.Status)).Inc()
+
+	return svc.storage.UpdateEmail(ctx, obj)
+}
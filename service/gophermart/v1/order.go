@@ -6,14 +6,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
 
 	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/tracing"
 	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
 )
 
 // AddOrder adds given order object to storage.
-func (svc *Service) AddOrder(ctx context.Context, obj model.Order) (model.Order, error) {
+func (svc *Service) AddOrder(ctx context.Context, obj model.Order) (_ model.Order, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.AddOrder")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	if err := validator.ValidateOrderNumber(obj.Number); err != nil {
 		return model.Order{}, err
 	}
@@ -23,11 +30,19 @@ func (svc *Service) AddOrder(ctx context.Context, obj model.Order) (model.Order,
 		return addedObj, err
 	}
 
+	logger := svc.Logger(ctx)
+	logger.Debug().Msgf("order added: %s", addedObj.Number)
+
 	return addedObj, nil
 }
 
 // GetOrders gets current user orders.
-func (svc *Service) GetOrders(ctx context.Context, userID uuid.UUID) ([]model.Order, error) {
+func (svc *Service) GetOrders(ctx context.Context, userID uuid.UUID) (_ []model.Order, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetOrders")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	objs, err := svc.storage.GetOrders(ctx, userID)
 	if err != nil {
 		return objs, err
@@ -38,19 +53,37 @@ func (svc *Service) GetOrders(ctx context.Context, userID uuid.UUID) ([]model.Or
 
 // orderStatusUpdater updates orders objects status.
 func (svc *Service) orderStatusUpdater(ctx context.Context) {
-	update := func() error {
-		updCtx, cancel := context.WithTimeout(context.Background(), svc.config.UpdaterTimeout)
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "orderStatusUpdater").Logger()
+
+	update := func() (err error) {
+		start := time.Now()
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "orderStatusUpdater.update")
+		defer func() {
+			tracing.EndSpan(span, err)
+			metrics.UpdaterTickDuration.Observe(time.Since(start).Seconds())
+		}()
+
+		// Pending orders are counted whatever the tick outcome is, so the gauge isn't stale
+		// while the accrual system fails
+		defer func() {
+			if countErr := svc.countPendingOrders(tickCtx); countErr != nil {
+				logger.Warn().Err(countErr).Msg("orderStatusUpdater:")
+			}
+		}()
+
+		updCtx, cancel := context.WithTimeout(tickCtx, svc.config.UpdaterTimeout)
 		defer cancel()
 
 		objs, err := svc.storage.GetStatusNewOrders(updCtx)
 		if err != nil {
 			return fmt.Errorf("get orders objects: %w", err)
 		}
+		metrics.UpdaterBatchSize.Observe(float64(len(objs)))
 
 		var orders []model.Order
 		var transactions []model.Transaction
 		for _, obj := range objs {
-			order, err := svc.provider.GetOrderAccruals(obj)
+			order, err := svc.provider.GetOrderAccruals(tickCtx, obj)
 			if err != nil {
 				return fmt.Errorf("accrual provider: %w", err)
 			}
@@ -67,16 +100,17 @@ func (svc *Service) orderStatusUpdater(ctx context.Context) {
 		}
 
 		if len(orders) > 0 {
-			updCtx, cancel = context.WithTimeout(context.Background(), svc.config.UpdaterTimeout)
+			updCtx, cancel = context.WithTimeout(tickCtx, svc.config.UpdaterTimeout)
 			defer cancel()
 
 			if err = svc.storage.UpdateOrders(updCtx, orders); err != nil {
 				return fmt.Errorf("update orders objects: %w", err)
 			}
+			logger.Debug().Msgf("orders updated: %d", len(orders))
 		}
 
 		if len(transactions) > 0 {
-			updCtx, cancel = context.WithTimeout(context.Background(), svc.config.UpdaterTimeout)
+			updCtx, cancel = context.WithTimeout(tickCtx, svc.config.UpdaterTimeout)
 			defer cancel()
 
 			if err = svc.storage.AddAccruals(updCtx, transactions); err != nil {
@@ -93,12 +127,28 @@ func (svc *Service) orderStatusUpdater(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("orderStatusUpdater closed")
+			logger.Info().Msg("orderStatusUpdater closed")
 			return
 		case <-ticker.C:
 			if err := update(); err != nil {
-				log.Warn().Err(err).Msg("orderStatusUpdater:")
+				logger.Warn().Err(err).Msg("orderStatusUpdater:")
 			}
 		}
 	}
 }
+
+// countPendingOrders updates pending orders gauge.
+func (svc *Service) countPendingOrders(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, svc.config.UpdaterTimeout)
+	defer cancel()
+
+	counts, err := svc.storage.GetPendingOrdersCount(ctx)
+	if err != nil {
+		return fmt.Errorf("get pending orders count: %w", err)
+	}
+	for status, count := range counts {
+		metrics.OrdersPending.WithLabelValues(status.String()).Set(float64(count))
+	}
+
+	return nil
+}
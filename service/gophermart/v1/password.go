@@ -0,0 +1,208 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+const (
+	newPasswordField = "new_password"
+
+	// passwordResetSendTimeout limits password reset token issuing and sending made in background.
+	passwordResetSendTimeout = 30 * time.Second
+	// passwordResetQueueSize limits password reset requests waiting to be sent, the following ones are dropped.
+	passwordResetQueueSize = 100
+
+	passwordResetSubject = "Password reset"
+	passwordResetBody    = "Use the following token to reset your password: %s\n" +
+		"The token expires at %s. If you didn't request a password reset, ignore this message."
+)
+
+// passwordResetRequest keeps queued password reset request data.
+type passwordResetRequest struct {
+	user        model.User
+	logger      zerolog.Logger
+	spanContext trace.SpanContext
+}
+
+// ChangePassword changes access token user password, revokes all user sessions and the access token.
+func (svc *Service) ChangePassword(ctx context.Context, accessToken model.AccessToken, currentPassword, newPassword string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.ChangePassword")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if currentPassword == "" {
+		return pkg.ErrWrongCredentials
+	}
+	if errs := svc.validateNewPassword(newPassword); len(errs) > 0 {
+		return errs
+	}
+
+	if err := svc.storage.ChangePassword(ctx, accessToken.UserID, currentPassword, newPassword); err != nil {
+		return fmt.Errorf("changing password: %w", err)
+	}
+
+	if err := svc.storage.RevokeAccessToken(ctx, accessToken); err != nil {
+		return fmt.Errorf("revoking access token: %w", err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset queues password reset token issuing and sending to the user.
+// Unknown logins are silently ignored and the token is issued and sent in background,
+// so registered logins can't be discovered by the response or its timing.
+// Requests made while the queue is full are dropped.
+func (svc *Service) RequestPasswordReset(ctx context.Context, login string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.RequestPasswordReset")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	login = validator.NormalizeLogin(login)
+	if errs := validator.ValidateLogin(login, validator.LoginPolicy{}); len(errs) > 0 {
+		return errs
+	}
+
+	user, err := svc.storage.GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	// Request context is canceled once the response is written, so the request keeps its logger and trace only
+	req := passwordResetRequest{user: user, logger: svc.Logger(ctx), spanContext: span.SpanContext()}
+	select {
+	case svc.passwordResets <- req:
+	default:
+		// The request isn't failed, so the response doesn't reveal the login is registered
+		req.logger.Warn().Msgf("password reset queue is full, request of user %s dropped", user.ID)
+	}
+
+	return nil
+}
+
+// passwordResetSender sends queued password reset tokens one by one until the service is closing,
+// requests queued by then are sent before it returns.
+func (svc *Service) passwordResetSender() {
+	defer svc.workers.Done()
+
+	for {
+		select {
+		case req := <-svc.passwordResets:
+			svc.sendQueuedPasswordReset(req)
+		case <-svc.closing:
+			for {
+				select {
+				case req := <-svc.passwordResets:
+					svc.sendQueuedPasswordReset(req)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// sendQueuedPasswordReset issues and sends password reset token of the queued request.
+func (svc *Service) sendQueuedPasswordReset(req passwordResetRequest) {
+	ctx := trace.ContextWithSpanContext(req.logger.WithContext(context.Background()), req.spanContext)
+	ctx, cancel := context.WithTimeout(ctx, passwordResetSendTimeout)
+	defer cancel()
+
+	if err := svc.sendPasswordResetToken(ctx, req.user); err != nil {
+		req.logger.Error().Err(err).Msgf("sending password reset token to user %s", req.user.ID)
+	}
+}
+
+// sendPasswordResetToken issues a password reset token and sends it to the user.
+func (svc *Service) sendPasswordResetToken(ctx context.Context, user model.User) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.sendPasswordResetToken")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	token, err := pkg.NewRandomToken()
+	if err != nil {
+		return err
+	}
+
+	obj, err := svc.storage.CreatePasswordResetToken(ctx, model.PasswordResetToken{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(svc.config.PasswordResetTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("creating password reset token: %w", err)
+	}
+
+	// Logins aren't necessarily email addresses, verified email takes precedence.
+	// Unverified emails (set before email verification was introduced) are skipped,
+	// they could be set by anyone having had access to the account.
+	to := user.Login
+	if user.Email != "" && !user.EmailVerifiedAt.IsZero() {
+		to = user.Email
+	}
+
+	msg := model.Message{
+		To:      to,
+		Subject: passwordResetSubject,
+		Body:    fmt.Sprintf(passwordResetBody, obj.Token, obj.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	if err = svc.notifier.Send(ctx, msg); err != nil {
+		return fmt.Errorf("sending password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets user password by password reset token and revokes all user sessions.
+func (svc *Service) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.ResetPassword")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if token == "" {
+		return pkg.ErrInvalidToken
+	}
+	if errs := svc.validateNewPassword(newPassword); len(errs) > 0 {
+		return errs
+	}
+
+	user, err := svc.storage.ResetPassword(ctx, token, newPassword)
+	if err != nil {
+		return fmt.Errorf("resetting password: %w", err)
+	}
+
+	// Password owner is confirmed, so lockout caused by guessing the old password is lifted
+	if err = svc.storage.DeleteLoginAttempts(ctx, loginAttemptsKey(user.Login)); err != nil {
+		logger := svc.Logger(ctx)
+		logger.Error().Err(err).Msg("resetting login attempts")
+	}
+
+	return nil
+}
+
+// validateNewPassword validates new password against the policy.
+func (svc *Service) validateNewPassword(password string) pkg.ValidationErrors {
+	errs := validator.ValidatePassword(password, svc.config.PasswordPolicy)
+	for i := range errs {
+		errs[i].Field = newPasswordField
+	}
+
+	return errs
+}
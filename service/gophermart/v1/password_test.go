@@ -0,0 +1,40 @@
+package gophermart
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+func TestServiceRequestPasswordResetQueue(t *testing.T) {
+	user := model.User{ID: uuid.New(), Login: "apricot"}
+	notifier := &fakeNotifier{}
+	svc := &Service{
+		config:         NewDefaultConfig(),
+		storage:        &fakeStorage{user: user},
+		notifier:       notifier,
+		passwordResets: make(chan passwordResetRequest, 1),
+		closing:        make(chan struct{}),
+	}
+
+	// The second request overflows the queue as nothing is sent yet
+	for i := 0; i < 2; i++ {
+		if err := svc.RequestPasswordReset(context.Background(), user.Login); err != nil {
+			t.Fatalf("RequestPasswordReset: %v", err)
+		}
+	}
+
+	svc.workers.Add(1)
+	go svc.passwordResetSender()
+
+	// Queued requests are sent before the sender returns
+	close(svc.closing)
+	svc.workers.Wait()
+
+	if len(notifier.sent) != 1 || notifier.sent[0].To != user.Login {
+		t.Errorf("sent: got %+v, want one message to %s", notifier.sent, user.Login)
+	}
+}
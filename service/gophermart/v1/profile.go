@@ -0,0 +1,48 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+// GetProfile gets user with profile data.
+func (svc *Service) GetProfile(ctx context.Context, userID uuid.UUID) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetProfile")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	obj, err := svc.storage.GetUser(ctx, userID)
+	if err != nil {
+		return model.User{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	return obj, nil
+}
+
+// UpdateProfile sets given user profile fields (clears the empty ones), nil fields are kept unchanged.
+func (svc *Service) UpdateProfile(ctx context.Context, userID uuid.UUID, obj model.ProfileUpdate) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.UpdateProfile")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	obj = validator.NormalizeProfileUpdate(obj)
+	if errs := validator.ValidateProfileUpdate(obj, time.Now()); len(errs) > 0 {
+		return model.User{}, errs
+	}
+
+	user, err := svc.storage.UpdateProfile(ctx, userID, obj)
+	if err != nil {
+		return model.User{}, fmt.Errorf("updating profile: %w", err)
+	}
+
+	return user, nil
+}
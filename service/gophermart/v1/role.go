@@ -0,0 +1,62 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
+)
+
+// SetUserRole sets role of given user.
+func (svc *Service) SetUserRole(ctx context.Context, userID uuid.UUID, role model.Role) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.SetUserRole")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if err := role.Validate(); err != nil {
+		return model.User{}, fmt.Errorf("%w: %v", pkg.ErrInvalidInput, err)
+	}
+
+	obj, err := svc.storage.SetUserRole(ctx, userID, role)
+	if err != nil {
+		return model.User{}, fmt.Errorf("setting user role: %w", err)
+	}
+
+	return obj, nil
+}
+
+// SetUserRoleByLogin sets role of user with given login.
+func (svc *Service) SetUserRoleByLogin(ctx context.Context, login string, role model.Role) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.SetUserRoleByLogin")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	user, err := svc.storage.GetUserByLogin(ctx, validator.NormalizeLogin(login))
+	if err != nil {
+		return model.User{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	return svc.SetUserRole(ctx, user.ID, role)
+}
+
+// userRole gets current role of given user.
+func (svc *Service) userRole(ctx context.Context, userID uuid.UUID) (model.Role, error) {
+	user, err := svc.storage.GetUser(ctx, userID)
+	if err != nil {
+		// Session of deleted user can't be used anymore
+		if errors.Is(err, pkg.ErrNotFound) {
+			return "", pkg.ErrInvalidToken
+		}
+		return "", fmt.Errorf("getting user: %w", err)
+	}
+
+	return user.Role, nil
+}
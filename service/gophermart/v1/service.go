@@ -3,12 +3,16 @@ package gophermart
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/secretbox"
 	"github.com/vstdy/gophermart/provider/accrual"
+	"github.com/vstdy/gophermart/provider/events"
+	"github.com/vstdy/gophermart/provider/notifier"
+	"github.com/vstdy/gophermart/provider/webhook"
 	"github.com/vstdy/gophermart/service/gophermart"
 	"github.com/vstdy/gophermart/storage"
 )
@@ -22,9 +26,18 @@ var _ gophermart.Service = (*Service)(nil)
 type (
 	// Service keeps service dependencies.
 	Service struct {
-		config   Config
-		provider accrual.Provider
-		storage  storage.Storage
+		config         Config
+		provider       accrual.Provider
+		notifier       notifier.Notifier
+		webhooks       webhook.Sender
+		publisher      events.Publisher
+		storage        storage.Storage
+		secretBox      *secretbox.Box
+		withoutWorkers bool
+		passwordResets chan passwordResetRequest
+		closing        chan struct{}
+		closeOnce      sync.Once
+		workers        sync.WaitGroup
 	}
 
 	// ServiceOption defines functional argument for Service constructor.
@@ -49,6 +62,33 @@ func WithProvider(p accrual.Provider) ServiceOption {
 	}
 }
 
+// WithNotifier sets Notifier.
+func WithNotifier(n notifier.Notifier) ServiceOption {
+	return func(svc *Service) error {
+		svc.notifier = n
+
+		return nil
+	}
+}
+
+// WithWebhookSender sets webhook Sender.
+func WithWebhookSender(s webhook.Sender) ServiceOption {
+	return func(svc *Service) error {
+		svc.webhooks = s
+
+		return nil
+	}
+}
+
+// WithEventPublisher sets events Publisher.
+func WithEventPublisher(p events.Publisher) ServiceOption {
+	return func(svc *Service) error {
+		svc.publisher = p
+
+		return nil
+	}
+}
+
 // WithStorage sets Storage.
 func WithStorage(st storage.Storage) ServiceOption {
 	return func(svc *Service) error {
@@ -58,10 +98,22 @@ func WithStorage(st storage.Storage) ServiceOption {
 	}
 }
 
+// WithoutBackgroundWorkers disables background workers (order status updater, webhook dispatcher, etc.),
+// e.g. for one-shot CLI commands.
+func WithoutBackgroundWorkers() ServiceOption {
+	return func(svc *Service) error {
+		svc.withoutWorkers = true
+
+		return nil
+	}
+}
+
 // New creates a new gophermart service.
 func New(ctx context.Context, opts ...ServiceOption) (*Service, error) {
 	svc := &Service{
-		config: NewDefaultConfig(),
+		config:         NewDefaultConfig(),
+		passwordResets: make(chan passwordResetRequest, passwordResetQueueSize),
+		closing:        make(chan struct{}),
 	}
 	for optIdx, opt := range opts {
 		if err := opt(svc); err != nil {
@@ -73,17 +125,70 @@ func New(ctx context.Context, opts ...ServiceOption) (*Service, error) {
 		return nil, fmt.Errorf("config validation: %w", err)
 	}
 
+	if err := svc.config.LoginPolicy.Compile(); err != nil {
+		return nil, fmt.Errorf("config validation: %w", err)
+	}
+
 	if svc.storage == nil {
 		return nil, fmt.Errorf("storage: nil")
 	}
 
+	if svc.provider == nil {
+		return nil, fmt.Errorf("provider: nil")
+	}
+
+	if svc.notifier == nil {
+		return nil, fmt.Errorf("notifier: nil")
+	}
+
+	if svc.webhooks == nil {
+		return nil, fmt.Errorf("webhook sender: nil")
+	}
+
+	if svc.publisher == nil {
+		return nil, fmt.Errorf("event publisher: nil")
+	}
+
+	secretBox, err := secretbox.New(svc.config.TwoFactor.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("totp secret box: %w", err)
+	}
+	svc.secretBox = secretBox
+
+	if svc.withoutWorkers {
+		return svc, nil
+	}
+
+	go svc.sealTwoFactorSecrets(ctx)
+
 	go svc.orderStatusUpdater(ctx)
+	go svc.webhookDispatcher(ctx)
+	go svc.eventRelay(ctx)
+	go svc.emailDispatcher(ctx)
+	go svc.inboxWriter(ctx)
+	go svc.inboxCleaner(ctx)
+
+	svc.workers.Add(1)
+	go svc.passwordResetSender()
 
 	return svc, nil
 }
 
-// Close closes all service dependencies.
+// Close waits for queued work to be done and closes all service dependencies.
 func (svc *Service) Close() error {
+	if svc.closing != nil {
+		svc.closeOnce.Do(func() {
+			close(svc.closing)
+		})
+		svc.workers.Wait()
+	}
+
+	if svc.publisher != nil {
+		if err := svc.publisher.Close(); err != nil {
+			return fmt.Errorf("closing event publisher: %w", err)
+		}
+	}
+
 	if svc.storage == nil {
 		return nil
 	}
@@ -95,9 +200,9 @@ func (svc *Service) Close() error {
 	return nil
 }
 
-// Logger returns logger with service context.
-func (svc *Service) Logger() zerolog.Logger {
-	logCtx := log.With().Str(logging.ServiceKey, serviceName)
+// Logger returns request scoped logger with service context.
+func (svc *Service) Logger(ctx context.Context) zerolog.Logger {
+	logCtx := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, serviceName)
 
 	return logCtx.Logger()
 }
@@ -0,0 +1,178 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const maxUserAgentLength = 512
+
+// CreateSession creates a new refresh token session for given user.
+func (svc *Service) CreateSession(ctx context.Context, userID uuid.UUID, client model.ClientInfo) (_ model.Session, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.CreateSession")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	refreshToken, err := pkg.NewRandomToken()
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	rawObj := model.Session{
+		UserID:       userID,
+		RefreshToken: refreshToken,
+		IP:           client.IP,
+		UserAgent:    truncateUserAgent(client.UserAgent),
+		ExpiresAt:    time.Now().Add(svc.config.RefreshTokenTTL),
+	}
+
+	obj, err := svc.storage.CreateSession(ctx, rawObj)
+	if err != nil {
+		return model.Session{}, fmt.Errorf("creating session: %w", err)
+	}
+
+	if obj.UserRole, err = svc.userRole(ctx, obj.UserID); err != nil {
+		return model.Session{}, err
+	}
+
+	return obj, nil
+}
+
+// RefreshSession rotates session refresh token.
+func (svc *Service) RefreshSession(ctx context.Context, refreshToken string, client model.ClientInfo) (_ model.Session, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.RefreshSession")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if refreshToken == "" {
+		return model.Session{}, pkg.ErrInvalidToken
+	}
+
+	newRefreshToken, err := pkg.NewRandomToken()
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	rawObj := model.Session{
+		RefreshToken: newRefreshToken,
+		IP:           client.IP,
+		UserAgent:    truncateUserAgent(client.UserAgent),
+		ExpiresAt:    time.Now().Add(svc.config.RefreshTokenTTL),
+	}
+
+	obj, err := svc.storage.RotateSession(ctx, refreshToken, rawObj)
+	if err != nil {
+		return model.Session{}, fmt.Errorf("rotating session: %w", err)
+	}
+
+	// Role changes take effect on the next refresh
+	if obj.UserRole, err = svc.userRole(ctx, obj.UserID); err != nil {
+		return model.Session{}, err
+	}
+
+	return obj, nil
+}
+
+// GetSessions gets active user sessions.
+func (svc *Service) GetSessions(ctx context.Context, userID uuid.UUID) (_ []model.Session, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetSessions")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	objs, err := svc.storage.GetSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting sessions: %w", err)
+	}
+
+	return objs, nil
+}
+
+// RevokeUserSession revokes user session by its id (signs out the device),
+// access tokens issued for the session are rejected since then.
+func (svc *Service) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.RevokeUserSession")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if err := svc.storage.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("revoking session: %w", err)
+	}
+
+	return nil
+}
+
+// Logout revokes session and access token.
+// Refresh token must belong to the access token user, otherwise pkg.ErrNotFound is returned.
+func (svc *Service) Logout(ctx context.Context, refreshToken string, accessToken model.AccessToken) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.Logout")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	// Session might be already revoked or expired, logout is done anyway
+	if refreshToken != "" {
+		err := svc.storage.RevokeSession(ctx, accessToken.UserID, refreshToken)
+		if err != nil && !errors.Is(err, pkg.ErrInvalidToken) {
+			return fmt.Errorf("revoking session: %w", err)
+		}
+	}
+
+	if err := svc.storage.RevokeAccessToken(ctx, accessToken); err != nil {
+		return fmt.Errorf("revoking access token: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateAccessToken checks that access token and its session are not revoked and returns current user role,
+// so role changes take effect without waiting for access token expiration.
+func (svc *Service) ValidateAccessToken(ctx context.Context, accessToken model.AccessToken) (_ model.Role, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.ValidateAccessToken")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	revoked, err := svc.storage.IsAccessTokenRevoked(ctx, accessToken)
+	if err != nil {
+		return "", fmt.Errorf("checking access token: %w", err)
+	}
+	if revoked {
+		return "", pkg.ErrInvalidToken
+	}
+
+	// Tokens issued before sessions were bound to them have no session ID
+	if accessToken.SessionID != uuid.Nil {
+		active, err := svc.storage.IsSessionActive(ctx, accessToken.SessionID)
+		if err != nil {
+			return "", fmt.Errorf("checking session: %w", err)
+		}
+		if !active {
+			return "", pkg.ErrInvalidToken
+		}
+	}
+
+	// Tokens of deleted users are rejected
+	return svc.userRole(ctx, accessToken.UserID)
+}
+
+// truncateUserAgent truncates User-Agent to the length stored.
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) <= maxUserAgentLength {
+		return userAgent
+	}
+
+	return string(runes[:maxUserAgentLength])
+}
@@ -0,0 +1,86 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage"
+)
+
+// fakeSessionStorage implements storage.Storage methods used by Logout.
+// Calling any other method panics on the nil embedded interface.
+type fakeSessionStorage struct {
+	storage.Storage
+	sessionUserID      uuid.UUID
+	refreshToken       string
+	sessionRevoked     bool
+	accessTokenRevoked bool
+}
+
+func (st *fakeSessionStorage) RevokeSession(_ context.Context, userID uuid.UUID, refreshToken string) error {
+	if refreshToken != st.refreshToken || userID != st.sessionUserID {
+		return pkg.ErrNotFound
+	}
+	if st.sessionRevoked {
+		return pkg.ErrInvalidToken
+	}
+	st.sessionRevoked = true
+
+	return nil
+}
+
+func (st *fakeSessionStorage) RevokeAccessToken(_ context.Context, _ model.AccessToken) error {
+	st.accessTokenRevoked = true
+
+	return nil
+}
+
+func TestServiceLogout(t *testing.T) {
+	const refreshToken = "refresh-token"
+
+	newService := func() (*Service, *fakeSessionStorage) {
+		st := &fakeSessionStorage{sessionUserID: uuid.New(), refreshToken: refreshToken}
+
+		return &Service{config: NewDefaultConfig(), storage: st}, st
+	}
+
+	t.Run("own session", func(t *testing.T) {
+		svc, st := newService()
+
+		if err := svc.Logout(context.Background(), refreshToken, model.AccessToken{UserID: st.sessionUserID}); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		if !st.sessionRevoked || !st.accessTokenRevoked {
+			t.Error("session or access token isn't revoked")
+		}
+	})
+
+	t.Run("already revoked session", func(t *testing.T) {
+		svc, st := newService()
+		st.sessionRevoked = true
+
+		if err := svc.Logout(context.Background(), refreshToken, model.AccessToken{UserID: st.sessionUserID}); err != nil {
+			t.Fatalf("Logout: %v", err)
+		}
+		if !st.accessTokenRevoked {
+			t.Error("access token isn't revoked")
+		}
+	})
+
+	t.Run("other user session", func(t *testing.T) {
+		svc, st := newService()
+
+		err := svc.Logout(context.Background(), refreshToken, model.AccessToken{UserID: uuid.New()})
+		if !errors.Is(err, pkg.ErrNotFound) {
+			t.Fatalf("Logout: got %v, want %v", err, pkg.ErrNotFound)
+		}
+		if st.sessionRevoked || st.accessTokenRevoked {
+			t.Error("session or access token revoked for other user refresh token")
+		}
+	})
+}
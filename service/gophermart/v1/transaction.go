@@ -6,11 +6,17 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/tracing"
 	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
 )
 
 // GetBalance gets current user balance.
-func (svc *Service) GetBalance(ctx context.Context, userID uuid.UUID) (float32, float32, error) {
+func (svc *Service) GetBalance(ctx context.Context, userID uuid.UUID) (_, _ float32, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetBalance")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	current, used, err := svc.storage.GetBalance(ctx, userID)
 	if err != nil {
 		return 0, 0, err
@@ -20,21 +26,34 @@ func (svc *Service) GetBalance(ctx context.Context, userID uuid.UUID) (float32,
 }
 
 // AddWithdrawal adds withdrawal.
-func (svc *Service) AddWithdrawal(ctx context.Context, transaction model.Transaction) error {
+func (svc *Service) AddWithdrawal(ctx context.Context, transaction model.Transaction) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.AddWithdrawal")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	if err := validator.ValidateOrderNumber(transaction.Order); err != nil {
 		return err
 	}
 
-	err := svc.storage.AddWithdrawal(ctx, transaction)
+	err = svc.storage.AddWithdrawal(ctx, transaction)
 	if err != nil {
 		return err
 	}
 
+	logger := svc.Logger(ctx)
+	logger.Debug().Msgf("withdrawal added: %s", transaction.Order)
+
 	return nil
 }
 
 // GetWithdrawals gets current user withdrawals.
-func (svc *Service) GetWithdrawals(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error) {
+func (svc *Service) GetWithdrawals(ctx context.Context, userID uuid.UUID) (_ []model.Transaction, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetWithdrawals")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	objs, err := svc.storage.GetWithdrawals(ctx, userID)
 	if err != nil {
 		return nil, err
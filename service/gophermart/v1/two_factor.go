@@ -0,0 +1,345 @@
+package gophermart
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/secretbox"
+	"github.com/vstdy/gophermart/pkg/totp"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const recoveryCodeLength = 10
+
+// EnrollTwoFactor starts TOTP two-factor authentication enrolment of given user.
+// 2FA is enabled once the enrolment is confirmed with a code.
+func (svc *Service) EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (_ model.TwoFactorEnrollment, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.EnrollTwoFactor")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	user, err := svc.storage.GetUser(ctx, userID)
+	if err != nil {
+		return model.TwoFactorEnrollment{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return model.TwoFactorEnrollment{}, err
+	}
+
+	sealedSecret, err := svc.secretBox.Seal(secret)
+	if err != nil {
+		return model.TwoFactorEnrollment{}, fmt.Errorf("sealing secret: %w", err)
+	}
+
+	if _, err = svc.storage.CreateTwoFactor(ctx, model.TwoFactor{UserID: userID, Secret: sealedSecret}); err != nil {
+		return model.TwoFactorEnrollment{}, fmt.Errorf("creating two-factor authentication: %w", err)
+	}
+
+	return model.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(svc.config.TwoFactor.Issuer, user.Login, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables TOTP two-factor authentication of given user
+// if code matches enrolled secret and returns recovery codes.
+func (svc *Service) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) (_ []string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.ConfirmTwoFactor")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	obj, err := svc.storage.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting two-factor authentication: %w", err)
+	}
+	if obj.Enabled() {
+		return nil, pkg.ErrAlreadyExists
+	}
+
+	secret, err := svc.openTwoFactorSecret(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok, err := totp.Validate(secret, normalizeTwoFactorCode(code), time.Now(), svc.config.TwoFactor.Skew)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, pkg.ErrWrongCredentials
+	}
+
+	recoveryCodes := make([]string, 0, svc.config.TwoFactor.RecoveryCodes)
+	for i := 0; i < svc.config.TwoFactor.RecoveryCodes; i++ {
+		recoveryCode, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+	}
+
+	storedCodes := make([]string, 0, len(recoveryCodes))
+	for _, recoveryCode := range recoveryCodes {
+		storedCodes = append(storedCodes, normalizeTwoFactorCode(recoveryCode))
+	}
+
+	if err = svc.storage.ConfirmTwoFactor(ctx, userID, step, storedCodes); err != nil {
+		return nil, fmt.Errorf("confirming two-factor authentication: %w", err)
+	}
+
+	return recoveryCodes, nil
+}
+
+// VerifyTwoFactor completes login of challenge user verifying TOTP or recovery code, the challenge is consumed.
+// Failed attempts are throttled the same way as failed logins.
+func (svc *Service) VerifyTwoFactor(ctx context.Context, challenge model.TwoFactorChallenge, code string, client model.ClientInfo) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.VerifyTwoFactor")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	user, err := svc.storage.GetUser(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			return model.User{}, pkg.ErrInvalidToken
+		}
+		return model.User{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	var attemptsKeys []model.LoginAttemptsKey
+	if svc.config.LoginThrottle.Enabled {
+		attemptsKeys = loginAttemptsKeys(user.Login, client)
+		if err = svc.acquireLoginAttempts(ctx, attemptsKeys); err != nil {
+			return model.User{}, err
+		}
+	}
+
+	if err = svc.verifyTwoFactorChallenge(ctx, challenge, code); err != nil {
+		// Attempt is already counted as failed
+		if svc.config.LoginThrottle.Enabled && !errors.Is(err, pkg.ErrWrongCredentials) {
+			svc.releaseLoginAttempts(ctx, attemptsKeys, false)
+		}
+		return model.User{}, err
+	}
+
+	if svc.config.LoginThrottle.Enabled {
+		svc.releaseLoginAttempts(ctx, attemptsKeys, true)
+	}
+
+	user.TwoFactorEnabled = true
+
+	return user, nil
+}
+
+// verifyTwoFactorChallenge verifies TOTP or recovery code of challenge user and consumes the challenge.
+func (svc *Service) verifyTwoFactorChallenge(ctx context.Context, challenge model.TwoFactorChallenge, code string) error {
+	obj, err := svc.storage.GetTwoFactor(ctx, challenge.UserID)
+	if err != nil && !errors.Is(err, pkg.ErrNotFound) {
+		return fmt.Errorf("getting two-factor authentication: %w", err)
+	}
+	if !obj.Enabled() {
+		return pkg.ErrInvalidToken
+	}
+
+	if err = svc.useTwoFactorCode(ctx, obj, normalizeTwoFactorCode(code)); err != nil {
+		return fmt.Errorf("verifying two-factor code: %w", err)
+	}
+
+	// Challenge is consumed on success only, so mistyped code doesn't require entering the password again
+	if err = svc.storage.ConsumeTwoFactorChallenge(ctx, challenge); err != nil {
+		return fmt.Errorf("consuming two-factor challenge: %w", err)
+	}
+
+	return nil
+}
+
+// DisableTwoFactor disables two-factor authentication of given user verifying password and TOTP or recovery code.
+// The code is consumed only after the password is verified. Failed attempts are throttled the same way as failed logins.
+func (svc *Service) DisableTwoFactor(ctx context.Context, userID uuid.UUID, password, code string, client model.ClientInfo) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.DisableTwoFactor")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if password == "" {
+		return pkg.ErrWrongCredentials
+	}
+
+	user, err := svc.storage.GetUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	obj, err := svc.storage.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getting two-factor authentication: %w", err)
+	}
+	if !obj.Enabled() {
+		return pkg.ErrNotFound
+	}
+
+	var attemptsKeys []model.LoginAttemptsKey
+	if svc.config.LoginThrottle.Enabled {
+		attemptsKeys = loginAttemptsKeys(user.Login, client)
+		if err = svc.acquireLoginAttempts(ctx, attemptsKeys); err != nil {
+			return err
+		}
+	}
+
+	if err = svc.disableTwoFactor(ctx, obj, password, code); err != nil {
+		// Attempt is already counted as failed
+		if svc.config.LoginThrottle.Enabled && !errors.Is(err, pkg.ErrWrongCredentials) {
+			svc.releaseLoginAttempts(ctx, attemptsKeys, false)
+		}
+		return err
+	}
+
+	if svc.config.LoginThrottle.Enabled {
+		svc.releaseLoginAttempts(ctx, attemptsKeys, true)
+	}
+
+	return nil
+}
+
+// disableTwoFactor verifies user password, consumes TOTP or recovery code and disables two-factor authentication.
+func (svc *Service) disableTwoFactor(ctx context.Context, obj model.TwoFactor, password, code string) error {
+	// Password goes first, so wrong one doesn't burn the code
+	if err := svc.storage.VerifyUserPassword(ctx, obj.UserID, password); err != nil {
+		return fmt.Errorf("verifying password: %w", err)
+	}
+
+	if err := svc.useTwoFactorCode(ctx, obj, normalizeTwoFactorCode(code)); err != nil {
+		return fmt.Errorf("verifying two-factor code: %w", err)
+	}
+
+	if err := svc.storage.DisableTwoFactor(ctx, obj.UserID, password); err != nil {
+		return fmt.Errorf("disabling two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// useTwoFactorCode consumes TOTP code (each one is accepted once) or recovery code.
+func (svc *Service) useTwoFactorCode(ctx context.Context, obj model.TwoFactor, code string) error {
+	if len(code) != recoveryCodeLength {
+		secret, err := svc.openTwoFactorSecret(obj)
+		if err != nil {
+			return err
+		}
+
+		step, ok, err := totp.Validate(secret, code, time.Now(), svc.config.TwoFactor.Skew)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return pkg.ErrWrongCredentials
+		}
+
+		return svc.storage.UseTwoFactorStep(ctx, obj.UserID, step)
+	}
+
+	return svc.storage.UseRecoveryCode(ctx, obj.UserID, code)
+}
+
+// openTwoFactorSecret decrypts user TOTP secret, secrets not sealed yet are returned as is.
+func (svc *Service) openTwoFactorSecret(obj model.TwoFactor) (string, error) {
+	if !secretbox.IsSealed(obj.Secret) {
+		return obj.Secret, nil
+	}
+
+	secret, err := svc.secretBox.Open(obj.Secret)
+	if err != nil {
+		return "", fmt.Errorf("opening secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// sealTwoFactorSecrets encrypts TOTP secrets stored in plaintext before encryption was introduced.
+func (svc *Service) sealTwoFactorSecrets(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "sealTwoFactorSecrets").Logger()
+
+	seal := func() (err error) {
+		ctx, span := tracing.Tracer().Start(logger.WithContext(ctx), "sealTwoFactorSecrets.seal")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		objs, err := svc.storage.GetTwoFactors(ctx)
+		if err != nil {
+			return fmt.Errorf("get two-factor authentications: %w", err)
+		}
+
+		var sealed int
+		for _, obj := range objs {
+			if secretbox.IsSealed(obj.Secret) {
+				continue
+			}
+
+			sealedSecret, err := svc.secretBox.Seal(obj.Secret)
+			if err != nil {
+				return fmt.Errorf("seal secret: %w", err)
+			}
+
+			// Secret replaced by re-enrolment meanwhile is sealed already
+			if err = svc.storage.ReplaceTwoFactorSecret(ctx, obj.UserID, obj.Secret, sealedSecret); err != nil {
+				return fmt.Errorf("replace user %s secret: %w", obj.UserID, err)
+			}
+			sealed++
+		}
+
+		if sealed > 0 {
+			logger.Info().Msgf("sealTwoFactorSecrets: %d secrets encrypted", sealed)
+		}
+
+		return nil
+	}
+
+	if err := seal(); err != nil {
+		logger.Warn().Err(err).Msg("sealTwoFactorSecrets:")
+	}
+}
+
+// isTwoFactorEnabled checks whether user has confirmed two-factor authentication.
+func (svc *Service) isTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	obj, err := svc.storage.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getting two-factor authentication: %w", err)
+	}
+
+	return obj.Enabled(), nil
+}
+
+// newRecoveryCode generates a new random recovery code formatted as "xxxxx-xxxxx".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating recovery code: %w", err)
+	}
+
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:recoveryCodeLength]
+
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:], nil
+}
+
+// normalizeTwoFactorCode removes separators users might type and lower-cases the code.
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,136 @@
+package gophermart
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage"
+)
+
+// fakeTwoFactorStorage implements storage.Storage methods used by two-factor authentication service methods.
+// Calling any other method panics on the nil embedded interface.
+type fakeTwoFactorStorage struct {
+	storage.Storage
+	user          model.User
+	recoveryCodes map[string]bool
+	disabled      bool
+	failures      int
+}
+
+func (st *fakeTwoFactorStorage) GetUser(_ context.Context, _ uuid.UUID) (model.User, error) {
+	return st.user, nil
+}
+
+func (st *fakeTwoFactorStorage) GetTwoFactor(_ context.Context, userID uuid.UUID) (model.TwoFactor, error) {
+	return model.TwoFactor{UserID: userID, ConfirmedAt: time.Now()}, nil
+}
+
+func (st *fakeTwoFactorStorage) VerifyUserPassword(_ context.Context, _ uuid.UUID, password string) error {
+	if password != "password" {
+		return pkg.ErrWrongCredentials
+	}
+
+	return nil
+}
+
+func (st *fakeTwoFactorStorage) UseRecoveryCode(_ context.Context, _ uuid.UUID, code string) error {
+	if !st.recoveryCodes[code] {
+		return pkg.ErrWrongCredentials
+	}
+	st.recoveryCodes[code] = false
+
+	return nil
+}
+
+func (st *fakeTwoFactorStorage) DisableTwoFactor(_ context.Context, _ uuid.UUID, _ string) error {
+	st.disabled = true
+
+	return nil
+}
+
+func (st *fakeTwoFactorStorage) AcquireLoginAttempt(_ context.Context, key model.LoginAttemptsKey, rule model.LoginAttemptsRule) (model.LoginAttempts, error) {
+	if key.Kind == model.LoginAttemptsByLogin {
+		if st.failures >= rule.LockoutThreshold {
+			return model.LoginAttempts{LoginAttemptsKey: key, Failures: st.failures}, pkg.ErrTooManyAttempts
+		}
+		st.failures++
+	}
+
+	return model.LoginAttempts{LoginAttemptsKey: key}, nil
+}
+
+func (st *fakeTwoFactorStorage) ReleaseLoginAttempt(_ context.Context, key model.LoginAttemptsKey, _ model.LoginAttemptsRule) error {
+	if key.Kind == model.LoginAttemptsByLogin {
+		st.failures--
+	}
+
+	return nil
+}
+
+func (st *fakeTwoFactorStorage) DeleteLoginAttempts(_ context.Context, _ model.LoginAttemptsKey) error {
+	st.failures = 0
+
+	return nil
+}
+
+func TestServiceDisableTwoFactor(t *testing.T) {
+	const recoveryCode = "abcdefghij"
+	client := model.ClientInfo{IP: "203.0.113.7"}
+
+	newService := func() (*Service, *fakeTwoFactorStorage) {
+		st := &fakeTwoFactorStorage{
+			user:          model.User{ID: uuid.New(), Login: "apricot"},
+			recoveryCodes: map[string]bool{recoveryCode: true},
+		}
+		config := NewDefaultConfig()
+		config.LoginThrottle.Enabled = true
+
+		return &Service{config: config, storage: st}, st
+	}
+
+	t.Run("wrong password keeps the code", func(t *testing.T) {
+		svc, st := newService()
+
+		err := svc.DisableTwoFactor(context.Background(), st.user.ID, "wrong", recoveryCode, client)
+		if !errors.Is(err, pkg.ErrWrongCredentials) {
+			t.Fatalf("DisableTwoFactor: got %v, want %v", err, pkg.ErrWrongCredentials)
+		}
+		if !st.recoveryCodes[recoveryCode] || st.disabled {
+			t.Fatal("recovery code burned or 2FA disabled with wrong password")
+		}
+		if st.failures != 1 {
+			t.Errorf("failures: got %d, want 1", st.failures)
+		}
+
+		if err = svc.DisableTwoFactor(context.Background(), st.user.ID, "password", "abcde-fghij", client); err != nil {
+			t.Fatalf("DisableTwoFactor: %v", err)
+		}
+		if st.recoveryCodes[recoveryCode] || !st.disabled {
+			t.Error("recovery code isn't consumed or 2FA isn't disabled")
+		}
+		if st.failures != 0 {
+			t.Errorf("failures: got %d, want reset", st.failures)
+		}
+	})
+
+	t.Run("code guesses locked out", func(t *testing.T) {
+		svc, st := newService()
+
+		var err error
+		for i := 0; i <= svc.config.LoginThrottle.LockoutThreshold; i++ {
+			err = svc.DisableTwoFactor(context.Background(), st.user.ID, "password", "0000000000", client)
+		}
+		if !errors.Is(err, pkg.ErrAccountLocked) {
+			t.Errorf("DisableTwoFactor: got %v, want %v", err, pkg.ErrAccountLocked)
+		}
+		if st.disabled {
+			t.Error("2FA disabled with wrong code")
+		}
+	})
+}
@@ -2,20 +2,27 @@ package gophermart
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/vstdy/gophermart/model"
 	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/tracing"
 	"github.com/vstdy/gophermart/service/gophermart/v1/validator"
 )
 
 // CreateUser creates a new model.User.
-func (svc *Service) CreateUser(ctx context.Context, rawObj model.User) (model.User, error) {
-	if err := validator.ValidateLogin(rawObj.Login); err != nil {
-		return model.User{}, fmt.Errorf("%w: login: %v", pkg.ErrInvalidInput, err)
-	}
-	if err := validator.ValidatePassword(rawObj.Password); err != nil {
-		return model.User{}, fmt.Errorf("%w: password: %v", pkg.ErrInvalidInput, err)
+func (svc *Service) CreateUser(ctx context.Context, rawObj model.User) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.CreateUser")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	rawObj.Login = validator.NormalizeLogin(rawObj.Login)
+	errs := validator.ValidateLogin(rawObj.Login, svc.config.LoginPolicy)
+	errs = append(errs, validator.ValidatePassword(rawObj.Password, svc.config.PasswordPolicy)...)
+	if len(errs) > 0 {
+		return model.User{}, errs
 	}
 
 	obj, err := svc.storage.CreateUser(ctx, rawObj)
@@ -27,18 +34,70 @@ func (svc *Service) CreateUser(ctx context.Context, rawObj model.User) (model.Us
 }
 
 // AuthenticateUser verifies the identity of credentials.
-func (svc *Service) AuthenticateUser(ctx context.Context, rawObj model.User) (model.User, error) {
-	if err := validator.ValidateLogin(rawObj.Login); err != nil {
-		return model.User{}, fmt.Errorf("%w: login: %v", pkg.ErrInvalidInput, err)
+// Failed attempts are throttled per login and per client IP.
+func (svc *Service) AuthenticateUser(ctx context.Context, rawObj model.User, client model.ClientInfo) (_ model.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.AuthenticateUser")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	// Policies aren't applied on authentication, so users registered before policy change can log in
+	rawObj.Login = validator.NormalizeLogin(rawObj.Login)
+	errs := validator.ValidateLogin(rawObj.Login, validator.LoginPolicy{})
+	errs = append(errs, validator.ValidatePassword(rawObj.Password, validator.PasswordPolicy{})...)
+	if len(errs) > 0 {
+		return model.User{}, errs
 	}
-	if err := validator.ValidatePassword(rawObj.Password); err != nil {
-		return model.User{}, fmt.Errorf("%w: password: %v", pkg.ErrInvalidInput, err)
+
+	var attemptsKeys []model.LoginAttemptsKey
+	if svc.config.LoginThrottle.Enabled {
+		attemptsKeys = loginAttemptsKeys(rawObj.Login, client)
+		if err := svc.acquireLoginAttempts(ctx, attemptsKeys); err != nil {
+			return model.User{}, err
+		}
 	}
 
 	obj, err := svc.storage.AuthenticateUser(ctx, rawObj)
 	if err != nil {
+		// Attempt is already counted as failed
+		if svc.config.LoginThrottle.Enabled && !errors.Is(err, pkg.ErrWrongCredentials) {
+			svc.releaseLoginAttempts(ctx, attemptsKeys, false)
+		}
 		return model.User{}, fmt.Errorf("authenticating user: %w", err)
 	}
 
+	// Login failures are reset only after the second factor is verified,
+	// otherwise knowing the password would allow guessing codes without limit
+	if obj.TwoFactorEnabled, err = svc.isTwoFactorEnabled(ctx, obj.ID); err != nil {
+		return model.User{}, err
+	}
+
+	if svc.config.LoginThrottle.Enabled {
+		svc.releaseLoginAttempts(ctx, attemptsKeys, !obj.TwoFactorEnabled)
+	}
+
 	return obj, nil
 }
+
+// DeleteUser deletes access token user after password confirmation, revokes all user sessions and the access token.
+// User login is anonymised and becomes available for registration, orders and transactions are kept.
+func (svc *Service) DeleteUser(ctx context.Context, accessToken model.AccessToken, password string) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.DeleteUser")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	if password == "" {
+		return pkg.ErrWrongCredentials
+	}
+
+	if err := svc.storage.DeleteUser(ctx, accessToken.UserID, password); err != nil {
+		return fmt.Errorf("deleting user: %w", err)
+	}
+
+	if err := svc.storage.RevokeAccessToken(ctx, accessToken); err != nil {
+		return fmt.Errorf("revoking access token: %w", err)
+	}
+
+	return nil
+}
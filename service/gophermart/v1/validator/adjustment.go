@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vstdy/gophermart/pkg"
+)
+
+const (
+	amountField = "amount"
+	reasonField = "reason"
+
+	// MaxAdjustmentAmount is the largest absolute amount of single manual balance adjustment.
+	MaxAdjustmentAmount = 1_000_000
+	// reasonColumnLength is a length of transactions.reason column.
+	reasonColumnLength = 500
+)
+
+// ValidateAdjustment validates manual balance adjustment:
+// amount (positive credit, negative debit) must be at least one cent by absolute value and reason is mandatory.
+func ValidateAdjustment(amount float32, reason string) pkg.ValidationErrors {
+	var errs pkg.ValidationErrors
+
+	// Amounts are stored in cents, so the smaller ones would be stored as zero
+	if int(amount*100) == 0 || amount > MaxAdjustmentAmount || amount < -MaxAdjustmentAmount {
+		errs = append(errs, pkg.ValidationError{
+			Field:   amountField,
+			Code:    CodeOutOfRange,
+			Message: fmt.Sprintf("must be at least 0.01 by absolute value and within [-%d, %d]", MaxAdjustmentAmount, MaxAdjustmentAmount),
+		})
+	}
+
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		errs = append(errs, pkg.ValidationError{Field: reasonField, Code: CodeEmpty, Message: "must not be empty"})
+	case utf8.RuneCountInString(reason) > reasonColumnLength:
+		errs = append(errs, pkg.ValidationError{
+			Field:   reasonField,
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters long", reasonColumnLength),
+		})
+	}
+
+	return errs
+}
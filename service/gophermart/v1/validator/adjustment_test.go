@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAdjustment(t *testing.T) {
+	testCases := []struct {
+		name      string
+		amount    float32
+		reason    string
+		wantCodes []string
+	}{
+		{name: "credit", amount: 50, reason: "Bonus"},
+		{name: "debit", amount: -50, reason: "Duplicate accrual"},
+		{name: "one cent", amount: 0.01, reason: "Bonus"},
+		{name: "max", amount: MaxAdjustmentAmount, reason: "Bonus"},
+		{name: "min", amount: -MaxAdjustmentAmount, reason: "Bonus"},
+		{name: "zero", amount: 0, reason: "Bonus", wantCodes: []string{CodeOutOfRange}},
+		{name: "below one cent", amount: 0.004, reason: "Bonus", wantCodes: []string{CodeOutOfRange}},
+		{name: "below one cent debit", amount: -0.009, reason: "Bonus", wantCodes: []string{CodeOutOfRange}},
+		{name: "above max", amount: MaxAdjustmentAmount + 1, reason: "Bonus", wantCodes: []string{CodeOutOfRange}},
+		{name: "below min", amount: -MaxAdjustmentAmount - 1, reason: "Bonus", wantCodes: []string{CodeOutOfRange}},
+		{name: "empty reason", amount: 50, reason: " \t", wantCodes: []string{CodeEmpty}},
+		{name: "long reason", amount: 50, reason: strings.Repeat("a", reasonColumnLength+1), wantCodes: []string{CodeTooLong}},
+		{name: "both invalid", amount: 0, reason: "", wantCodes: []string{CodeOutOfRange, CodeEmpty}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertCodes(t, ValidateAdjustment(tc.amount, tc.reason), tc.wantCodes)
+		})
+	}
+}
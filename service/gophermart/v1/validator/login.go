@@ -2,13 +2,132 @@ package validator
 
 import (
 	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/vstdy/gophermart/pkg"
 )
 
-// ValidateLogin validates login.
-func ValidateLogin(login string) error {
-	if login == "" {
-		return fmt.Errorf("empty")
+const (
+	loginField = "login"
+	// loginColumnLength is a length of users.login column.
+	loginColumnLength = 100
+)
+
+// LoginPolicy defines login format requirements.
+// Zero value only requires login to be non-empty.
+type LoginPolicy struct {
+	MinLength int `mapstructure:"login_min_length"`
+	MaxLength int `mapstructure:"login_max_length"`
+	// Pattern is a regular expression allowed logins must match.
+	// It is compiled on every validation unless precompiled with Compile.
+	Pattern string `mapstructure:"login_pattern"`
+	// Email requires login to be an email address.
+	Email bool `mapstructure:"login_email"`
+
+	pattern *regexp.Regexp
+}
+
+// Validate performs a basic validation.
+func (policy LoginPolicy) Validate() error {
+	if policy.MinLength < 1 {
+		return fmt.Errorf("login_min_length field: must be positive")
+	}
+
+	if policy.MaxLength < policy.MinLength || policy.MaxLength > loginColumnLength {
+		return fmt.Errorf("login_max_length field: must be within [login_min_length, %d]", loginColumnLength)
+	}
+
+	if _, err := regexp.Compile(policy.Pattern); err != nil {
+		return fmt.Errorf("login_pattern field: %w", err)
 	}
 
 	return nil
 }
+
+// Compile compiles Pattern, so it isn't compiled on every validation.
+func (policy *LoginPolicy) Compile() error {
+	policy.pattern = nil
+	if policy.Pattern == "" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(policy.Pattern)
+	if err != nil {
+		return fmt.Errorf("login_pattern field: %w", err)
+	}
+	policy.pattern = pattern
+
+	return nil
+}
+
+// NewDefaultLoginPolicy builds a LoginPolicy with default values.
+func NewDefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{
+		MinLength: 3,
+		MaxLength: loginColumnLength,
+		Pattern:   `^[\p{L}\p{N}._@+-]+$`,
+	}
+}
+
+// NormalizeLogin trims surrounding whitespaces and applies Unicode NFKC normalization,
+// so visually identical logins are stored and compared the same way.
+func NormalizeLogin(login string) string {
+	return norm.NFKC.String(strings.TrimSpace(login))
+}
+
+// ValidateLogin validates normalized login against the policy.
+func ValidateLogin(login string, policy LoginPolicy) pkg.ValidationErrors {
+	if login == "" {
+		return pkg.ValidationErrors{{Field: loginField, Code: CodeEmpty, Message: "empty"}}
+	}
+
+	var errs pkg.ValidationErrors
+	addErr := func(code, msg string) {
+		errs = append(errs, pkg.ValidationError{Field: loginField, Code: code, Message: msg})
+	}
+
+	length := utf8.RuneCountInString(login)
+	if length < policy.MinLength {
+		addErr(CodeTooShort, fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		addErr(CodeTooLong, fmt.Sprintf("must be at most %d characters long", policy.MaxLength))
+	}
+
+	if !utf8.ValidString(login) || strings.IndexFunc(login, unicode.IsControl) >= 0 {
+		addErr(CodeInvalidCharacters, "contains invalid characters")
+	} else if !policy.matchPattern(login) {
+		addErr(CodeInvalidCharacters, "contains invalid characters")
+	}
+
+	if policy.Email {
+		if addr, err := mail.ParseAddress(login); err != nil || addr.Address != login {
+			addErr(CodeInvalidEmail, "must be an email address")
+		}
+	}
+
+	return errs
+}
+
+// matchPattern reports whether login matches Pattern (invalid Pattern matches nothing).
+func (policy LoginPolicy) matchPattern(login string) bool {
+	if policy.Pattern == "" {
+		return true
+	}
+
+	pattern := policy.pattern
+	if pattern == nil {
+		var err error
+		if pattern, err = regexp.Compile(policy.Pattern); err != nil {
+			return false
+		}
+	}
+
+	return pattern.MatchString(login)
+}
@@ -0,0 +1,85 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLogin(t *testing.T) {
+	testCases := []struct {
+		name  string
+		login string
+		want  string
+	}{
+		{name: "as is", login: "apricot", want: "apricot"},
+		{name: "surrounding whitespaces", login: " \tapricot\n", want: "apricot"},
+		{name: "fullwidth", login: "ａｐｒｉｃｏｔ", want: "apricot"},
+		{name: "ligature", login: "ﬁg", want: "fig"},
+		{name: "composed", login: "café", want: "café"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NormalizeLogin(tc.login); got != tc.want {
+				t.Errorf("NormalizeLogin(%q): got %q, want %q", tc.login, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateLogin(t *testing.T) {
+	policy := NewDefaultLoginPolicy()
+	if err := policy.Compile(); err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	emailPolicy := policy
+	emailPolicy.Email = true
+
+	uncompiledPolicy := NewDefaultLoginPolicy()
+
+	invalidPatternPolicy := NewDefaultLoginPolicy()
+	invalidPatternPolicy.Pattern = "["
+
+	testCases := []struct {
+		name      string
+		login     string
+		policy    LoginPolicy
+		wantCodes []string
+	}{
+		{name: "valid", login: "apricot", policy: policy},
+		{name: "valid with symbols", login: "apricot.tree+42@example.com", policy: policy},
+		{name: "valid unicode", login: "абрикос", policy: policy},
+		{name: "empty", login: "", policy: policy, wantCodes: []string{CodeEmpty}},
+		{name: "too short", login: "ap", policy: policy, wantCodes: []string{CodeTooShort}},
+		{name: "too long", login: strings.Repeat("a", 101), policy: policy, wantCodes: []string{CodeTooLong}},
+		{name: "space", login: "apricot tree", policy: policy, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "control character", login: "apri\x00cot", policy: policy, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "invalid UTF-8", login: "apri\xffcot", policy: policy, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "email", login: "apricot@example.com", policy: emailPolicy},
+		{name: "not email", login: "apricot", policy: emailPolicy, wantCodes: []string{CodeInvalidEmail}},
+		{name: "uncompiled pattern", login: "apricot", policy: uncompiledPolicy},
+		{name: "uncompiled pattern space", login: "apricot tree", policy: uncompiledPolicy, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "invalid pattern", login: "apricot", policy: invalidPatternPolicy, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "zero policy", login: "a b", policy: LoginPolicy{}},
+		{name: "zero policy empty", login: "", policy: LoginPolicy{}, wantCodes: []string{CodeEmpty}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertCodes(t, ValidateLogin(tc.login, tc.policy), tc.wantCodes)
+		})
+	}
+}
+
+func TestLoginPolicyCompile(t *testing.T) {
+	policy := NewDefaultLoginPolicy()
+	policy.Pattern = "["
+
+	if err := policy.Validate(); err == nil {
+		t.Error("Validate: invalid pattern accepted")
+	}
+	if err := policy.Compile(); err == nil {
+		t.Error("Compile: invalid pattern accepted")
+	}
+}
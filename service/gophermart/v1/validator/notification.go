@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+)
+
+const (
+	emailField              = "email"
+	emailNotificationsField = "email_notifications"
+
+	// emailColumnLength is a length of users.email column.
+	emailColumnLength = 254
+)
+
+// NormalizeEmail trims surrounding whitespaces of email address.
+func NormalizeEmail(email string) string {
+	return strings.TrimSpace(email)
+}
+
+// ValidateNotificationSettings validates normalized notification settings:
+// email must be a bare address (empty one clears it) and notification types must be known ones.
+// Email notifications can't be enabled without email address.
+func ValidateNotificationSettings(obj model.NotificationSettings) pkg.ValidationErrors {
+	var errs pkg.ValidationErrors
+
+	switch {
+	case obj.Email == "":
+		for notificationType, enabled := range obj.EmailEnabled {
+			if enabled {
+				errs = append(errs, pkg.ValidationError{
+					Field:   emailField,
+					Code:    CodeEmpty,
+					Message: fmt.Sprintf("must be set to enable %s email notifications", notificationType),
+				})
+				break
+			}
+		}
+	default:
+		if err := validateEmail(obj.Email); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+
+	for notificationType := range obj.EmailEnabled {
+		if err := notificationType.Validate(); err != nil {
+			errs = append(errs, pkg.ValidationError{
+				Field:   emailNotificationsField + "." + string(notificationType),
+				Code:    CodeUnknown,
+				Message: "unknown notification type",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateEmail validates non-empty normalized email address, it must be a bare address.
+func validateEmail(email string) *pkg.ValidationError {
+	if len(email) > emailColumnLength {
+		return &pkg.ValidationError{
+			Field:   emailField,
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters long", emailColumnLength),
+		}
+	}
+
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return &pkg.ValidationError{Field: emailField, Code: CodeInvalidEmail, Message: "must be an email address"}
+	}
+
+	return nil
+}
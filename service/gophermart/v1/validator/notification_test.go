@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+func TestValidateNotificationSettings(t *testing.T) {
+	testCases := []struct {
+		name      string
+		obj       model.NotificationSettings
+		wantCodes []string
+	}{
+		{name: "nothing"},
+		{
+			name: "enabled",
+			obj: model.NotificationSettings{
+				Email:        "apricot@example.com",
+				EmailEnabled: map[model.NotificationType]bool{model.NotificationAccrualProcessed: true},
+			},
+		},
+		{
+			name: "disabled without email",
+			obj: model.NotificationSettings{
+				EmailEnabled: map[model.NotificationType]bool{model.NotificationWithdrawalMade: false},
+			},
+		},
+		{
+			name: "enabled without email",
+			obj: model.NotificationSettings{
+				EmailEnabled: map[model.NotificationType]bool{
+					model.NotificationWithdrawalMade: true,
+					model.NotificationOrderInvalid:   true,
+				},
+			},
+			wantCodes: []string{CodeEmpty},
+		},
+		{name: "display name", obj: model.NotificationSettings{Email: "Apricot <apricot@example.com>"}, wantCodes: []string{CodeInvalidEmail}},
+		{name: "not an email", obj: model.NotificationSettings{Email: "apricot"}, wantCodes: []string{CodeInvalidEmail}},
+		{
+			name:      "long email",
+			obj:       model.NotificationSettings{Email: strings.Repeat("a", 243) + "@example.com"},
+			wantCodes: []string{CodeTooLong},
+		},
+		{
+			name: "unknown type",
+			obj: model.NotificationSettings{
+				Email:        "apricot@example.com",
+				EmailEnabled: map[model.NotificationType]bool{"birthday": true},
+			},
+			wantCodes: []string{CodeUnknown},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertCodes(t, ValidateNotificationSettings(tc.obj), tc.wantCodes)
+		})
+	}
+}
@@ -1,14 +1,111 @@
 package validator
 
 import (
+	"bufio"
+	_ "embed"
 	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vstdy/gophermart/pkg"
 )
 
-// ValidatePassword validates password.
-func ValidatePassword(login string) error {
-	if login == "" {
-		return fmt.Errorf("empty")
+const passwordField = "password"
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+// commonPasswords is a set of lower-cased common passwords.
+var commonPasswords = parseCommonPasswords(commonPasswordsList)
+
+// PasswordPolicy defines password requirements.
+// Zero value only requires password to be non-empty.
+type PasswordPolicy struct {
+	MinLength     int  `mapstructure:"password_min_length"`
+	RequireUpper  bool `mapstructure:"password_require_upper"`
+	RequireLower  bool `mapstructure:"password_require_lower"`
+	RequireDigit  bool `mapstructure:"password_require_digit"`
+	RequireSymbol bool `mapstructure:"password_require_symbol"`
+	RejectCommon  bool `mapstructure:"password_reject_common"`
+}
+
+// Validate performs a basic validation.
+func (policy PasswordPolicy) Validate() error {
+	if policy.MinLength < 1 {
+		return fmt.Errorf("password_min_length field: must be positive")
 	}
 
 	return nil
 }
+
+// NewDefaultPasswordPolicy builds a PasswordPolicy with default values.
+func NewDefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    8,
+		RejectCommon: true,
+	}
+}
+
+// ValidatePassword validates password against the policy.
+func ValidatePassword(password string, policy PasswordPolicy) pkg.ValidationErrors {
+	if password == "" {
+		return pkg.ValidationErrors{{Field: passwordField, Code: CodeEmpty, Message: "empty"}}
+	}
+
+	var errs pkg.ValidationErrors
+	addErr := func(code, msg string) {
+		errs = append(errs, pkg.ValidationError{Field: passwordField, Code: code, Message: msg})
+	}
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		addErr(CodeTooShort, fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		addErr(CodeMissingUppercase, "must contain an uppercase letter")
+	}
+	if policy.RequireLower && !hasLower {
+		addErr(CodeMissingLowercase, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !hasDigit {
+		addErr(CodeMissingDigit, "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		addErr(CodeMissingSymbol, "must contain a symbol")
+	}
+
+	if policy.RejectCommon {
+		if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+			addErr(CodeTooCommon, "is too common")
+		}
+	}
+
+	return errs
+}
+
+// parseCommonPasswords parses newline separated passwords list.
+func parseCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" && !strings.HasPrefix(password, "#") {
+			passwords[strings.ToLower(password)] = struct{}{}
+		}
+	}
+
+	return passwords
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	strict := PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		RejectCommon:  true,
+	}
+
+	testCases := []struct {
+		name      string
+		password  string
+		policy    PasswordPolicy
+		wantCodes []string
+	}{
+		{name: "empty", policy: strict, wantCodes: []string{CodeEmpty}},
+		{name: "zero policy", password: "a"},
+		{name: "strict", password: "Apricot-1990", policy: strict},
+		{name: "runes counted", password: "пароль", policy: PasswordPolicy{MinLength: 6}},
+		{name: "short", password: "Apr-1990", policy: strict, wantCodes: []string{CodeTooShort}},
+		{
+			name:     "missing classes",
+			password: "          ",
+			policy:   strict,
+			wantCodes: []string{
+				CodeMissingUppercase, CodeMissingLowercase, CodeMissingDigit, CodeMissingSymbol,
+			},
+		},
+		{name: "common", password: "PassWord", policy: NewDefaultPasswordPolicy(), wantCodes: []string{CodeTooCommon}},
+		{name: "common allowed", password: "password", policy: PasswordPolicy{MinLength: 8}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidatePassword(tc.password, tc.policy)
+			assertCodes(t, errs, tc.wantCodes)
+			for _, err := range errs {
+				if err.Field != passwordField {
+					t.Errorf("field: got %s, want %s", err.Field, passwordField)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCommonPasswords(t *testing.T) {
+	passwords := parseCommonPasswords("# comment\n\n  QWERTY  \nletmein\n")
+
+	if len(passwords) != 2 {
+		t.Fatalf("passwords: got %v, want 2", passwords)
+	}
+	for _, password := range []string{"qwerty", "letmein"} {
+		if _, ok := passwords[password]; !ok {
+			t.Errorf("passwords: %s is missing", password)
+		}
+	}
+}
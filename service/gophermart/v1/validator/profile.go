@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+)
+
+const (
+	displayNameField = "display_name"
+	phoneField       = "phone"
+	birthdayField    = "birthday"
+	languageField    = "language"
+
+	// displayNameColumnLength is a length of users.display_name column.
+	displayNameColumnLength = 64
+	// languageColumnLength is a length of users.language column.
+	languageColumnLength = 35
+	// maxAge is the largest age birthday is accepted for.
+	maxAge = 150
+)
+
+// phonePattern matches E.164 phone number.
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizeProfileUpdate trims profile fields, normalizes display name (Unicode NFKC),
+// removes phone number separators and canonicalizes language tag if it's valid.
+func NormalizeProfileUpdate(obj model.ProfileUpdate) model.ProfileUpdate {
+	normalize := func(value *string, fn func(string) string) *string {
+		if value == nil {
+			return nil
+		}
+		normalized := fn(strings.TrimSpace(*value))
+
+		return &normalized
+	}
+
+	obj.DisplayName = normalize(obj.DisplayName, norm.NFKC.String)
+	obj.Phone = normalize(obj.Phone, strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace)
+	obj.Birthday = normalize(obj.Birthday, func(s string) string { return s })
+	obj.Language = normalize(obj.Language, func(s string) string {
+		if tag, err := language.Parse(s); err == nil {
+			return tag.String()
+		}
+		return s
+	})
+
+	return obj
+}
+
+// ValidateProfileUpdate validates normalized profile changes, empty values (clearing the fields) are valid.
+// Phone number must be in E.164 format, birthday must be a past date, language must be BCP 47 tag.
+// Email is rejected: it is set with notification settings only, along with their checks.
+func ValidateProfileUpdate(obj model.ProfileUpdate, now time.Time) pkg.ValidationErrors {
+	var errs pkg.ValidationErrors
+
+	if obj.DisplayName != nil && *obj.DisplayName != "" {
+		name := *obj.DisplayName
+		if utf8.RuneCountInString(name) > displayNameColumnLength {
+			errs = append(errs, pkg.ValidationError{
+				Field:   displayNameField,
+				Code:    CodeTooLong,
+				Message: fmt.Sprintf("must be at most %d characters long", displayNameColumnLength),
+			})
+		}
+		if !utf8.ValidString(name) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+			errs = append(errs, pkg.ValidationError{Field: displayNameField, Code: CodeInvalidCharacters, Message: "contains invalid characters"})
+		}
+	}
+
+	if obj.Email != nil {
+		errs = append(errs, pkg.ValidationError{
+			Field:   emailField,
+			Code:    CodeReadOnly,
+			Message: "must be set with notification settings",
+		})
+	}
+
+	if obj.Phone != nil && *obj.Phone != "" && !phonePattern.MatchString(*obj.Phone) {
+		errs = append(errs, pkg.ValidationError{
+			Field:   phoneField,
+			Code:    CodeInvalidPhone,
+			Message: "must be a phone number in international format, e.g. +12025550123",
+		})
+	}
+
+	if obj.Birthday != nil && *obj.Birthday != "" {
+		birthday, err := time.Parse(model.BirthdayLayout, *obj.Birthday)
+		switch {
+		case err != nil:
+			errs = append(errs, pkg.ValidationError{Field: birthdayField, Code: CodeInvalidDate, Message: "must be a date in YYYY-MM-DD format"})
+		case birthday.After(now) || birthday.Before(now.AddDate(-maxAge, 0, 0)):
+			errs = append(errs, pkg.ValidationError{
+				Field:   birthdayField,
+				Code:    CodeOutOfRange,
+				Message: fmt.Sprintf("must be a past date within %d years", maxAge),
+			})
+		}
+	}
+
+	if obj.Language != nil && *obj.Language != "" {
+		if _, err := language.Parse(*obj.Language); err != nil || len(*obj.Language) > languageColumnLength {
+			errs = append(errs, pkg.ValidationError{
+				Field:   languageField,
+				Code:    CodeInvalidLanguage,
+				Message: "must be a language tag, e.g. en or pt-BR",
+			})
+		}
+	}
+
+	return errs
+}
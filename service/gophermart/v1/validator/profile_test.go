@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+func TestNormalizeProfileUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	obj := NormalizeProfileUpdate(model.ProfileUpdate{
+		DisplayName: str(" Ａpricot "),
+		Phone:       str("+1 (202) 555-0123"),
+		Birthday:    str(" 1990-05-17 "),
+		Language:    str("pt-br"),
+	})
+
+	for _, tc := range []struct {
+		field string
+		got   *string
+		want  string
+	}{
+		{field: "display_name", got: obj.DisplayName, want: "Apricot"},
+		{field: "phone", got: obj.Phone, want: "+12025550123"},
+		{field: "birthday", got: obj.Birthday, want: "1990-05-17"},
+		{field: "language", got: obj.Language, want: "pt-BR"},
+	} {
+		if tc.got == nil || *tc.got != tc.want {
+			t.Errorf("%s: got %v, want %q", tc.field, tc.got, tc.want)
+		}
+	}
+	if obj.Email != nil {
+		t.Errorf("email: got %q, want nil", *obj.Email)
+	}
+}
+
+func TestValidateProfileUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		obj       model.ProfileUpdate
+		wantCodes []string
+	}{
+		{name: "nothing"},
+		{
+			name: "valid",
+			obj: model.ProfileUpdate{
+				DisplayName: str("Apricot"),
+				Phone:       str("+12025550123"),
+				Birthday:    str("1990-05-17"),
+				Language:    str("en"),
+			},
+		},
+		{
+			name: "cleared",
+			obj:  model.ProfileUpdate{DisplayName: str(""), Phone: str(""), Birthday: str(""), Language: str("")},
+		},
+		{name: "long display name", obj: model.ProfileUpdate{DisplayName: str(strings.Repeat("a", 65))}, wantCodes: []string{CodeTooLong}},
+		{name: "control character", obj: model.ProfileUpdate{DisplayName: str("apri\ncot")}, wantCodes: []string{CodeInvalidCharacters}},
+		{name: "email", obj: model.ProfileUpdate{Email: str("apricot@example.com")}, wantCodes: []string{CodeReadOnly}},
+		{name: "email cleared", obj: model.ProfileUpdate{Email: str("")}, wantCodes: []string{CodeReadOnly}},
+		{name: "local phone", obj: model.ProfileUpdate{Phone: str("2025550123")}, wantCodes: []string{CodeInvalidPhone}},
+		{name: "malformed birthday", obj: model.ProfileUpdate{Birthday: str("17.05.1990")}, wantCodes: []string{CodeInvalidDate}},
+		{name: "future birthday", obj: model.ProfileUpdate{Birthday: str("2026-10-20")}, wantCodes: []string{CodeOutOfRange}},
+		{name: "ancient birthday", obj: model.ProfileUpdate{Birthday: str("1870-01-01")}, wantCodes: []string{CodeOutOfRange}},
+		{name: "unknown language", obj: model.ProfileUpdate{Language: str("not a language")}, wantCodes: []string{CodeInvalidLanguage}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertCodes(t, ValidateProfileUpdate(tc.obj, now), tc.wantCodes)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package validator
+
+// Validation error codes.
+const (
+	CodeEmpty             = "empty"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeInvalidEmail      = "invalid_email"
+	CodeMissingUppercase  = "missing_uppercase"
+	CodeMissingLowercase  = "missing_lowercase"
+	CodeMissingDigit      = "missing_digit"
+	CodeMissingSymbol     = "missing_symbol"
+	CodeTooCommon         = "too_common"
+	CodeOutOfRange        = "out_of_range"
+	CodeUnknown           = "unknown"
+	CodeInvalidPhone      = "invalid_phone"
+	CodeInvalidDate       = "invalid_date"
+	CodeInvalidLanguage   = "invalid_language"
+	CodeReadOnly          = "read_only"
+)
@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/vstdy/gophermart/pkg"
+)
+
+// assertCodes checks validation errors have exactly the wanted codes in order.
+func assertCodes(t *testing.T, errs pkg.ValidationErrors, wantCodes []string) {
+	t.Helper()
+
+	codes := make([]string, 0, len(errs))
+	for _, err := range errs {
+		codes = append(codes, err.Code)
+	}
+
+	if len(codes) != len(wantCodes) {
+		t.Fatalf("codes: got %v, want %v", codes, wantCodes)
+	}
+	for i := range codes {
+		if codes[i] != wantCodes[i] {
+			t.Fatalf("codes: got %v, want %v", codes, wantCodes)
+		}
+	}
+}
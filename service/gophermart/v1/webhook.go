@@ -0,0 +1,181 @@
+package gophermart
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/tracing"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 1000
+	// maxDeliveryErrorLength limits stored delivery error length.
+	maxDeliveryErrorLength = 1000
+)
+
+// GetWebhookDeliveries gets webhook deliveries with given status (any if empty), latest first.
+// Zero limit means the default one.
+func (svc *Service) GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus, limit int) (_ []model.WebhookDelivery, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.GetWebhookDeliveries")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown delivery status %s", pkg.ErrInvalidInput, status)
+	}
+
+	if limit == 0 {
+		limit = defaultDeliveriesLimit
+	}
+	if limit < 0 || limit > maxDeliveriesLimit {
+		return nil, fmt.Errorf("%w: limit must be within [1, %d]", pkg.ErrInvalidInput, maxDeliveriesLimit)
+	}
+
+	objs, err := svc.storage.GetWebhookDeliveries(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook deliveries: %w", err)
+	}
+
+	return objs, nil
+}
+
+// ReplayWebhookDeliveries schedules given webhook deliveries (all dead ones if none given)
+// for immediate delivery. Returns the number of deliveries scheduled.
+func (svc *Service) ReplayWebhookDeliveries(ctx context.Context, ids []uuid.UUID) (_ int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "Service.ReplayWebhookDeliveries")
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	count, err := svc.storage.ReplayWebhookDeliveries(ctx, ids)
+	if err != nil {
+		return 0, fmt.Errorf("replaying webhook deliveries: %w", err)
+	}
+
+	return count, nil
+}
+
+// webhookDispatcher enqueues outbox events to subscribed webhooks and delivers them.
+func (svc *Service) webhookDispatcher(ctx context.Context) {
+	logger := svc.Logger(ctx).With().Str(logging.JobKey, "webhookDispatcher").Logger()
+	config := svc.config.Webhook
+
+	subscriptions := make(map[string]WebhookSubscription, len(config.Subscriptions))
+	eventSubscriptions := make(map[model.EventType][]string)
+	for _, subscription := range config.Subscriptions {
+		subscriptions[subscription.Name] = subscription
+		for _, event := range subscription.Events {
+			eventSubscriptions[event] = append(eventSubscriptions[event], subscription.Name)
+		}
+	}
+
+	dispatch := func() (err error) {
+		tickCtx, span := tracing.Tracer().Start(logger.WithContext(context.Background()), "webhookDispatcher.dispatch")
+		defer func() {
+			tracing.EndSpan(span, err)
+		}()
+
+		// Claimed deliveries are leased for the tick timeout, so they're retried if the tick is interrupted
+		tickCtx, cancel := context.WithTimeout(tickCtx, config.DispatchTimeout)
+		defer cancel()
+
+		if _, err = svc.storage.EnqueueWebhookDeliveries(tickCtx, eventSubscriptions, config.BatchSize); err != nil {
+			return fmt.Errorf("enqueue deliveries: %w", err)
+		}
+
+		objs, err := svc.storage.ClaimWebhookDeliveries(tickCtx, config.BatchSize, config.DispatchTimeout)
+		if err != nil {
+			return fmt.Errorf("claim deliveries: %w", err)
+		}
+
+		for _, obj := range objs {
+			subscription, ok := subscriptions[obj.Webhook]
+			if err = svc.deliverWebhook(tickCtx, obj, subscription, ok); err != nil {
+				return fmt.Errorf("delivery %s: %w", obj.ID, err)
+			}
+		}
+
+		return nil
+	}
+
+	ticker := time.NewTicker(config.DispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Msg("webhookDispatcher closed")
+			return
+		case <-ticker.C:
+			if err := dispatch(); err != nil {
+				logger.Warn().Err(err).Msg("webhookDispatcher:")
+			}
+		}
+	}
+}
+
+// deliverWebhook makes delivery attempt and saves its result.
+// Deliveries of subscriptions removed from config become dead at once.
+func (svc *Service) deliverWebhook(ctx context.Context, obj model.WebhookDelivery, subscription WebhookSubscription, subscribed bool) error {
+	config := svc.config.Webhook
+
+	var err error
+	obj.LastStatusCode = 0
+	if subscribed {
+		obj.LastStatusCode, err = svc.webhooks.Send(ctx, model.WebhookMessage{
+			URL:        subscription.URL,
+			Secret:     subscription.Secret,
+			DeliveryID: obj.ID,
+			Event:      obj.Event,
+		})
+		if ctx.Err() != nil {
+			// The attempt is interrupted by the tick timeout, delivery is retried after the lease
+			return ctx.Err()
+		}
+	} else {
+		err = fmt.Errorf("webhook %s is not configured", obj.Webhook)
+	}
+
+	now := time.Now()
+	obj.Attempts++
+	obj.NextAttemptAt = now
+	switch {
+	case err == nil:
+		obj.Status = model.WebhookDeliveryDelivered
+		obj.DeliveredAt = now
+		obj.LastError = ""
+	case !subscribed || obj.Attempts >= config.MaxAttempts:
+		obj.Status = model.WebhookDeliveryDead
+		obj.LastError = truncateError(err)
+		logger := svc.Logger(ctx)
+		logger.Warn().Err(err).Msgf("webhook %s delivery %s is dead after %d attempts", obj.Webhook, obj.ID, obj.Attempts)
+	default:
+		obj.Status = model.WebhookDeliveryPending
+		obj.NextAttemptAt = now.Add(config.retryDelay(obj.Attempts))
+		obj.LastError = truncateError(err)
+	}
+	metrics.WebhookDeliveriesTotal.WithLabelValues(string(obj.Status)).Inc()
+
+	return svc.storage.UpdateWebhookDelivery(ctx, obj)
+}
+
+// truncateError returns error message truncated to be stored.
+func truncateError(err error) string {
+	msg := []rune(err.Error())
+	if len(msg) > maxDeliveryErrorLength {
+		msg = msg[:maxDeliveryErrorLength]
+	}
+
+	return string(msg)
+}
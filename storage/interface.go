@@ -4,6 +4,7 @@ package storage
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -13,10 +14,100 @@ import (
 type Storage interface {
 	io.Closer
 
+	// Ping checks storage connectivity.
+	Ping(ctx context.Context) error
+	// CheckMigrations checks that all migrations are applied.
+	CheckMigrations(ctx context.Context) error
+
 	// CreateUser adds given objects to storage.
 	CreateUser(ctx context.Context, obj model.User) (model.User, error)
 	// AuthenticateUser verifies the identity of credentials.
 	AuthenticateUser(ctx context.Context, obj model.User) (model.User, error)
+	// VerifyUserPassword checks user password, ErrWrongCredentials is returned if it doesn't match.
+	VerifyUserPassword(ctx context.Context, userID uuid.UUID, password string) error
+
+	// GetUser gets user by id.
+	GetUser(ctx context.Context, userID uuid.UUID) (model.User, error)
+	// GetUserByLogin gets user by login (case-insensitively).
+	GetUserByLogin(ctx context.Context, login string) (model.User, error)
+	// SearchUsers gets users whose login contains query (case-insensitively) ordered by login.
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]model.User, error)
+	// ChangePassword verifies user current password, sets the new one and revokes user sessions.
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	// DeleteUser verifies user password, soft-deletes the user anonymising the login and profile and revokes user sessions.
+	DeleteUser(ctx context.Context, userID uuid.UUID, password string) error
+	// SetUserRole sets user role.
+	SetUserRole(ctx context.Context, userID uuid.UUID, role model.Role) (model.User, error)
+	// UpdateProfile sets given user profile fields (clears the empty ones), nil fields are kept unchanged.
+	UpdateProfile(ctx context.Context, userID uuid.UUID, obj model.ProfileUpdate) (model.User, error)
+	// CreatePasswordResetToken adds given password reset token to storage,
+	// previously issued user tokens are deleted.
+	CreatePasswordResetToken(ctx context.Context, obj model.PasswordResetToken) (model.PasswordResetToken, error)
+	// ResetPassword consumes password reset token, sets user password and revokes user sessions.
+	ResetPassword(ctx context.Context, token, password string) (model.User, error)
+	// CreateEmailVerificationToken checks user password and adds given email verification token to storage,
+	// previously issued user tokens are deleted.
+	CreateEmailVerificationToken(ctx context.Context, password string, obj model.EmailVerificationToken) (model.EmailVerificationToken, error)
+	// VerifyEmail consumes user email verification token and sets user email to the verified one.
+	VerifyEmail(ctx context.Context, userID uuid.UUID, token string) error
+	// ClearEmail checks user password and clears user email.
+	ClearEmail(ctx context.Context, userID uuid.UUID, password string) error
+
+	// CreateTwoFactor adds given unconfirmed two-factor authentication to storage,
+	// previous unconfirmed enrolment of the user is replaced.
+	CreateTwoFactor(ctx context.Context, obj model.TwoFactor) (model.TwoFactor, error)
+	// GetTwoFactor gets user two-factor authentication.
+	GetTwoFactor(ctx context.Context, userID uuid.UUID) (model.TwoFactor, error)
+	// ConfirmTwoFactor enables user two-factor authentication marking given time step as used
+	// and replaces user recovery codes with the given ones.
+	ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, step int64, recoveryCodes []string) error
+	// UseTwoFactorStep marks given time step as used,
+	// ErrWrongCredentials is returned if the step (or later one) is already used.
+	UseTwoFactorStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// UseRecoveryCode consumes user recovery code, ErrWrongCredentials is returned if there is no such unused code.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) error
+	// ConsumeTwoFactorChallenge marks given challenge as used, ErrInvalidToken is returned if it is already used.
+	ConsumeTwoFactorChallenge(ctx context.Context, obj model.TwoFactorChallenge) error
+	// DisableTwoFactor verifies user password and deletes user two-factor authentication and recovery codes.
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, password string) error
+	// GetTwoFactors gets two-factor authentications of all users.
+	GetTwoFactors(ctx context.Context) ([]model.TwoFactor, error)
+	// ReplaceTwoFactorSecret replaces user two-factor authentication secret if it is still the old one.
+	ReplaceTwoFactorSecret(ctx context.Context, userID uuid.UUID, oldSecret, newSecret string) error
+
+	// AcquireLoginAttempt counts login attempt by given key as failed in advance and locks further attempts
+	// according to the rule atomically. ErrTooManyAttempts is returned along with the counter if attempts are locked.
+	AcquireLoginAttempt(ctx context.Context, key model.LoginAttemptsKey, rule model.LoginAttemptsRule) (model.LoginAttempts, error)
+	// ReleaseLoginAttempt takes back login attempt acquired by given key, the lock is lifted if it isn't reached anymore.
+	ReleaseLoginAttempt(ctx context.Context, key model.LoginAttemptsKey, rule model.LoginAttemptsRule) error
+	// DeleteLoginAttempts resets failed login attempts and lock by given key.
+	DeleteLoginAttempts(ctx context.Context, key model.LoginAttemptsKey) error
+
+	// CreateSession adds given session to storage.
+	CreateSession(ctx context.Context, obj model.Session) (model.Session, error)
+	// RotateSession replaces active session refresh token with the one from given session.
+	RotateSession(ctx context.Context, refreshToken string, obj model.Session) (model.Session, error)
+	// RevokeSession revokes user session by its refresh token.
+	RevokeSession(ctx context.Context, userID uuid.UUID, refreshToken string) error
+	// GetSessions gets active user sessions, most recently used first.
+	GetSessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	// RevokeUserSession revokes active user session by its id.
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	// IsSessionActive checks whether session exists and isn't revoked or expired.
+	IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	// RevokeAccessToken adds given access token to revoked ones until it expires.
+	RevokeAccessToken(ctx context.Context, obj model.AccessToken) error
+	// IsAccessTokenRevoked checks whether given access token is revoked.
+	IsAccessTokenRevoked(ctx context.Context, obj model.AccessToken) (bool, error)
+
+	// CreateAPIKey adds given merchant API key to storage.
+	CreateAPIKey(ctx context.Context, obj model.APIKey) (model.APIKey, error)
+	// GetAPIKeys gets all merchant API keys.
+	GetAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	// RevokeAPIKey revokes merchant API key by its id.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// AuthenticateAPIKey gets active (not revoked or expired) merchant API key by the key and records its use.
+	AuthenticateAPIKey(ctx context.Context, key string) (model.APIKey, error)
 
 	// AddOrder adds given order to storage.
 	AddOrder(ctx context.Context, obj model.Order) (model.Order, error)
@@ -26,6 +117,8 @@ type Storage interface {
 	UpdateOrders(ctx context.Context, objs []model.Order) error
 	// GetOrders gets current user orders.
 	GetOrders(ctx context.Context, userID uuid.UUID) ([]model.Order, error)
+	// GetPendingOrdersCount gets number of not yet processed orders by status.
+	GetPendingOrdersCount(ctx context.Context) (map[model.OrderStatus]int, error)
 
 	// GetBalance gets current user balance.
 	GetBalance(ctx context.Context, userID uuid.UUID) (float32, float32, error)
@@ -35,4 +128,61 @@ type Storage interface {
 	AddWithdrawal(ctx context.Context, transaction model.Transaction) error
 	// GetWithdrawals gets current user withdrawals.
 	GetWithdrawals(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error)
+	// AddAdjustment adds manual balance adjustment, debits exceeding current balance are rejected.
+	AddAdjustment(ctx context.Context, obj model.Transaction) (model.Transaction, error)
+	// GetAdjustments gets user manual balance adjustments, latest first.
+	GetAdjustments(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error)
+
+	// VerifyAuditLog verifies audit log hash chain and reports the first broken link.
+	VerifyAuditLog(ctx context.Context) (model.AuditVerification, error)
+
+	// ClaimUnpublishedEvents gets outbox events neither published nor dead and due for an attempt, oldest first,
+	// and postpones them by lease.
+	ClaimUnpublishedEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error)
+	// MarkEventsPublished marks given outbox events published.
+	MarkEventsPublished(ctx context.Context, ids []uuid.UUID) error
+	// FailEventPublishing saves failed publishing attempt of outbox event, the event becomes dead after maxAttempts.
+	FailEventPublishing(ctx context.Context, id uuid.UUID, reason string, maxAttempts int) (bool, error)
+	// ReleaseEvents makes given claimed outbox events due for an attempt at once.
+	ReleaseEvents(ctx context.Context, ids []uuid.UUID) error
+
+	// EnqueueWebhookDeliveries creates deliveries of outbox events not yet enqueued
+	// to webhooks subscribed to the event type. Returns the number of deliveries created.
+	EnqueueWebhookDeliveries(ctx context.Context, subscriptions map[model.EventType][]string, limit int) (int, error)
+	// ClaimWebhookDeliveries gets pending deliveries due for an attempt and postpones them by lease.
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	// UpdateWebhookDelivery saves delivery attempt result.
+	UpdateWebhookDelivery(ctx context.Context, obj model.WebhookDelivery) error
+	// GetWebhookDeliveries gets deliveries with given status (any if empty), latest first.
+	GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error)
+	// ReplayWebhookDeliveries schedules given deliveries (all dead ones if none given) for immediate delivery.
+	ReplayWebhookDeliveries(ctx context.Context, ids []uuid.UUID) (int, error)
+
+	// GetNotificationSettings gets user email (whether it is verified) and stored notification preferences.
+	GetNotificationSettings(ctx context.Context, userID uuid.UUID) (model.NotificationSettings, error)
+	// SetNotificationSettings sets given notification preferences, user email is kept.
+	SetNotificationSettings(ctx context.Context, userID uuid.UUID, obj model.NotificationSettings) error
+	// GetPendingNotificationEvents gets outbox events not yet turned into notifications, oldest first.
+	GetPendingNotificationEvents(ctx context.Context, limit int) ([]model.Event, error)
+	// EnqueueEmails adds given emails to the sending queue and marks given outbox events notified.
+	// Returns the number of emails enqueued.
+	EnqueueEmails(ctx context.Context, eventIDs []uuid.UUID, objs []model.Email) (int, error)
+	// ClaimEmails gets pending emails due for a sending attempt and postpones them by lease.
+	ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]model.Email, error)
+	// UpdateEmail saves email sending attempt result.
+	UpdateEmail(ctx context.Context, obj model.Email) error
+
+	// GetPendingInboxEvents gets outbox events not yet turned into inbox notifications, oldest first.
+	GetPendingInboxEvents(ctx context.Context, limit int) ([]model.Event, error)
+	// AddNotifications adds given notifications to users inbox and marks given outbox events handled.
+	// Returns the number of notifications added.
+	AddNotifications(ctx context.Context, eventIDs []uuid.UUID, objs []model.Notification) (int, error)
+	// GetNotifications gets user notifications (unread ones only if unreadOnly is set), latest first,
+	// along with user notification counters.
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (model.NotificationList, error)
+	// MarkNotificationsRead marks given user notifications (all if none given) read.
+	// Returns the number of user unread notifications left.
+	MarkNotificationsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int, error)
+	// DeleteNotificationsBefore deletes notifications created before given time.
+	DeleteNotificationsBefore(ctx context.Context, before time.Time) (int, error)
 }
@@ -0,0 +1,106 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const apiKeyTableName = "merchant_api_key"
+
+// CreateAPIKey adds given merchant API key to storage.
+func (st *Storage) CreateAPIKey(ctx context.Context, obj model.APIKey) (model.APIKey, error) {
+	logger := st.Logger(ctx, withTable(apiKeyTableName), withOperation("insert"))
+
+	dbObj := schema.NewAPIKeyFromCanonical(obj)
+
+	_, err := st.db.NewInsert().
+		Model(&dbObj).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	addedObj.Key = obj.Key
+
+	logger.Info().Msgf("API key created %s (%s)", addedObj.ID, addedObj.Name)
+
+	return addedObj, nil
+}
+
+// GetAPIKeys gets all merchant API keys.
+func (st *Storage) GetAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	var dbObjs []schema.APIKey
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Order("created_at").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]model.APIKey, 0, len(dbObjs))
+	for _, dbObj := range dbObjs {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// RevokeAPIKey revokes merchant API key by its id.
+func (st *Storage) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	logger := st.Logger(ctx, withTable(apiKeyTableName), withOperation("revoke"))
+
+	res, err := st.db.NewUpdate().
+		Model((*schema.APIKey)(nil)).
+		Set("revoked_at = NOW()").
+		Where("id = ?", id).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return pkg.ErrNotFound
+	}
+
+	logger.Info().Msgf("API key revoked %s", id)
+
+	return nil
+}
+
+// AuthenticateAPIKey gets active (not revoked or expired) merchant API key by the key and records its use.
+func (st *Storage) AuthenticateAPIKey(ctx context.Context, key string) (model.APIKey, error) {
+	var dbObj schema.APIKey
+
+	res, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("last_used_at = NOW()").
+		Where("key_hash = ?", schema.HashToken(key)).
+		Where("revoked_at IS NULL").
+		Where("expires_at IS NULL OR expires_at > NOW()").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return model.APIKey{}, pkg.ErrInvalidToken
+	}
+
+	return dbObj.ToCanonical()
+}
@@ -0,0 +1,153 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg/audit"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const (
+	auditLogTableName = "audit_log"
+
+	// auditLockKey is the transaction level advisory lock key serializing audit log appends,
+	// so entries are chained in the commit order.
+	auditLockKey = 0x6175646974 // "audit"
+	// auditVerifyBatchSize is the number of entries read at once on verification.
+	auditVerifyBatchSize = 1000
+)
+
+// auditBalanceChange runs write changing user balance within tx and appends audit log entry of it.
+// write returns the operation reference (order number or transaction ID).
+// Returns the appended entry.
+func (st *Storage) auditBalanceChange(
+	ctx context.Context,
+	tx bun.Tx,
+	action model.AuditAction,
+	userID uuid.UUID,
+	write func() (string, error),
+) (schema.AuditEntry, error) {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", auditLockKey); err != nil {
+		return schema.AuditEntry{}, fmt.Errorf("locking audit log: %w", err)
+	}
+
+	before, err := userBalance(ctx, tx, userID)
+	if err != nil {
+		return schema.AuditEntry{}, err
+	}
+
+	reference, err := write()
+	if err != nil {
+		return schema.AuditEntry{}, err
+	}
+
+	after, err := userBalance(ctx, tx, userID)
+	if err != nil {
+		return schema.AuditEntry{}, err
+	}
+
+	var last schema.AuditEntry
+	err = tx.NewSelect().
+		Model(&last).
+		Column("seq", "hash").
+		Order("seq DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return schema.AuditEntry{}, fmt.Errorf("reading last audit log entry: %w", err)
+	}
+
+	actor := audit.ActorFromContext(ctx)
+	dbObj := schema.AuditEntry{
+		Seq:           last.Seq + 1,
+		Action:        string(action),
+		ActorType:     string(actor.Type),
+		ActorID:       actor.ID,
+		RequestID:     audit.RequestIDFromContext(ctx),
+		UserID:        userID,
+		Reference:     reference,
+		Amount:        after - before,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+		PrevHash:      last.Hash,
+		// PostgreSQL keeps microseconds, so the hash is computed of the value stored
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	dbObj.Hash = dbObj.ComputeHash()
+
+	if _, err = tx.NewInsert().Model(&dbObj).Exec(ctx); err != nil {
+		return schema.AuditEntry{}, fmt.Errorf("appending audit log entry: %w", err)
+	}
+
+	return dbObj, nil
+}
+
+// userBalance gets current user balance in hundredths.
+func userBalance(ctx context.Context, db bun.IDB, userID uuid.UUID) (int64, error) {
+	var balance int64
+
+	err := db.NewSelect().
+		Model((*schema.Transaction)(nil)).
+		ColumnExpr("coalesce(sum(accrual) - sum(withdrawal), 0)").
+		Where("user_id = ?", userID).
+		Scan(ctx, &balance)
+	if err != nil {
+		return 0, fmt.Errorf("reading user balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// VerifyAuditLog verifies audit log hash chain and reports the first broken link.
+func (st *Storage) VerifyAuditLog(ctx context.Context) (model.AuditVerification, error) {
+	logger := st.Logger(ctx, withTable(auditLogTableName), withOperation("verify"))
+
+	var result model.AuditVerification
+	var prevHash string
+
+	for {
+		var dbObjs []schema.AuditEntry
+		err := st.db.NewSelect().
+			Model(&dbObjs).
+			Where("seq > ?", result.Checked).
+			Order("seq ASC").
+			Limit(auditVerifyBatchSize).
+			Scan(ctx)
+		if err != nil {
+			return model.AuditVerification{}, err
+		}
+
+		for _, dbObj := range dbObjs {
+			switch {
+			case dbObj.Seq != result.Checked+1:
+				result.BrokenSeq = result.Checked + 1
+				result.Reason = fmt.Sprintf("entry is missing (next found is %d)", dbObj.Seq)
+			case dbObj.PrevHash != prevHash:
+				result.BrokenSeq = dbObj.Seq
+				result.Reason = "previous entry hash mismatch"
+			case dbObj.Hash != dbObj.ComputeHash():
+				result.BrokenSeq = dbObj.Seq
+				result.Reason = "entry hash mismatch (entry data altered)"
+			}
+			if !result.Intact() {
+				logger.Warn().Msgf("Audit log chain broken at %d: %s", result.BrokenSeq, result.Reason)
+				return result, nil
+			}
+
+			result.Checked = dbObj.Seq
+			prevHash = dbObj.Hash
+		}
+
+		if len(dbObjs) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
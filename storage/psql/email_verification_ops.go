@@ -0,0 +1,162 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const emailVerificationTokenTableName = "email_verification_token"
+
+// CreateEmailVerificationToken checks user password and adds given email verification token to storage,
+// previously issued user tokens are deleted.
+func (st *Storage) CreateEmailVerificationToken(ctx context.Context, password string, obj model.EmailVerificationToken) (model.EmailVerificationToken, error) {
+	logger := st.Logger(ctx, withTable(emailVerificationTokenTableName), withOperation("insert"))
+
+	dbObj := schema.NewEmailVerificationTokenFromCanonical(obj)
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := st.checkUserPassword(ctx, tx, dbObj.UserID, password); err != nil {
+			return err
+		}
+
+		if err := deleteEmailVerificationTokens(ctx, tx, dbObj.UserID); err != nil {
+			return err
+		}
+
+		_, err := tx.NewInsert().
+			Model(&dbObj).
+			Returning("*").
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return model.EmailVerificationToken{}, err
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.EmailVerificationToken{}, err
+	}
+	addedObj.Token = obj.Token
+
+	logger.Info().Msgf("Email verification token created for user %s", addedObj.UserID)
+
+	return addedObj, nil
+}
+
+// VerifyEmail consumes user email verification token and sets user email to the verified one.
+func (st *Storage) VerifyEmail(ctx context.Context, userID uuid.UUID, token string) error {
+	logger := st.Logger(ctx, withTable(emailVerificationTokenTableName), withOperation("verify"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var dbObj schema.EmailVerificationToken
+		res, err := tx.NewUpdate().
+			Model(&dbObj).
+			Set("used_at = NOW()").
+			Where("token_hash = ?", schema.HashToken(token)).
+			Where("user_id = ?", userID).
+			Where("used_at IS NULL").
+			Where("expires_at > NOW()").
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return pkg.ErrInvalidToken
+		}
+
+		res, err = tx.NewUpdate().
+			Model((*schema.User)(nil)).
+			Set("email = ?", dbObj.Email).
+			Set("email_verified_at = NOW()").
+			Set("updated_at = NOW()").
+			Where("id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return pkg.ErrInvalidToken
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("User email verified %s", userID)
+
+	return nil
+}
+
+// ClearEmail checks user password, clears user email and deletes pending email verification tokens.
+func (st *Storage) ClearEmail(ctx context.Context, userID uuid.UUID, password string) error {
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("clear_email"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := st.checkUserPassword(ctx, tx, userID, password); err != nil {
+			return err
+		}
+
+		_, err := tx.NewUpdate().
+			Model((*schema.User)(nil)).
+			Set("email = NULL").
+			Set("email_verified_at = NULL").
+			Set("updated_at = NOW()").
+			Where("id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		return deleteEmailVerificationTokens(ctx, tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("User email cleared %s", userID)
+
+	return nil
+}
+
+// checkUserPassword locks user row till the end of transaction and checks user password.
+func (st *Storage) checkUserPassword(ctx context.Context, tx bun.Tx, userID uuid.UUID, password string) error {
+	dbObj := schema.User{ID: userID}
+	err := tx.NewSelect().
+		Model(&dbObj).
+		WherePK().
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkg.ErrNotFound
+		}
+		return err
+	}
+
+	_, err = dbObj.ComparePasswords(st.hasher, password)
+
+	return err
+}
+
+// deleteEmailVerificationTokens deletes user email verification tokens.
+func deleteEmailVerificationTokens(ctx context.Context, db bun.IDB, userID uuid.UUID) error {
+	_, err := db.NewDelete().
+		Model((*schema.EmailVerificationToken)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+
+	return err
+}
@@ -2,11 +2,16 @@ package psql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/uptrace/bun"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/vstdy/gophermart/pkg/logging"
+	"github.com/vstdy/gophermart/pkg/tracing"
 )
 
 type queryHook struct {
@@ -20,11 +25,29 @@ func newQueryHook(storage *Storage) queryHook {
 }
 
 func (h queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	ctx, _ = tracing.Tracer().Start(
+		ctx,
+		"psql."+event.Operation(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(event.Operation()),
+		),
+	)
+
 	return ctx
 }
 
 func (h queryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
-	logger := h.st.Logger()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(semconv.DBStatementKey.String(event.Query))
+	err := event.Err
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+
+	logger := h.st.Logger(ctx)
 	logger.Debug().
 		Dur(logging.RequestDurKey, time.Since(event.StartTime)).
 		Msg(event.Query)
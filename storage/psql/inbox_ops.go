@@ -0,0 +1,172 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const notificationTableName = "notification"
+
+// GetPendingInboxEvents gets outbox events not yet turned into inbox notifications, oldest first.
+func (st *Storage) GetPendingInboxEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	var dbObjs schema.OutboxEvents
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("inbox_enqueued_at IS NULL").
+		Order("seq").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// AddNotifications adds given notifications to users inbox and marks given outbox events handled.
+// An event makes at most one notification, so events handled concurrently don't make duplicates.
+// Returns the number of notifications added.
+func (st *Storage) AddNotifications(ctx context.Context, eventIDs []uuid.UUID, objs []model.Notification) (int, error) {
+	logger := st.Logger(ctx, withTable(notificationTableName), withOperation("add"))
+
+	var created int
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(objs) > 0 {
+			dbObjs := make([]schema.Notification, 0, len(objs))
+			for _, obj := range objs {
+				dbObjs = append(dbObjs, schema.NewNotificationFromCanonical(obj))
+			}
+
+			res, err := tx.NewInsert().
+				Model(&dbObjs).
+				On("CONFLICT (event_id) DO NOTHING").
+				Returning("NULL").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			created = int(rows)
+		}
+
+		if len(eventIDs) == 0 {
+			return nil
+		}
+
+		_, err := tx.NewUpdate().
+			Model((*schema.OutboxEvent)(nil)).
+			Set("inbox_enqueued_at = NOW()").
+			Where("id IN (?)", bun.In(eventIDs)).
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if created > 0 {
+		logger.Debug().Msgf("Notifications added: %d", created)
+	}
+
+	return created, nil
+}
+
+// GetNotifications gets user notifications (unread ones only if unreadOnly is set), latest first,
+// along with user total and unread notifications counters.
+func (st *Storage) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) (model.NotificationList, error) {
+	var total, unread int
+	err := st.db.NewSelect().
+		Model((*schema.Notification)(nil)).
+		ColumnExpr("count(*) AS total").
+		ColumnExpr("count(*) FILTER (WHERE read_at IS NULL) AS unread").
+		Where("user_id = ?", userID).
+		Scan(ctx, &total, &unread)
+	if err != nil {
+		return model.NotificationList{}, err
+	}
+
+	var dbObjs schema.Notifications
+	query := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("user_id = ?", userID).
+		Order("created_at DESC", "id DESC").
+		Limit(limit).
+		Offset(offset)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err = query.Scan(ctx); err != nil {
+		return model.NotificationList{}, err
+	}
+
+	objs, err := dbObjs.ToCanonical()
+	if err != nil {
+		return model.NotificationList{}, err
+	}
+
+	return model.NotificationList{
+		Notifications: objs,
+		Total:         total,
+		Unread:        unread,
+	}, nil
+}
+
+// MarkNotificationsRead marks given user notifications (all if none given) read.
+// Returns the number of user unread notifications left.
+func (st *Storage) MarkNotificationsRead(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int, error) {
+	query := st.db.NewUpdate().
+		Model((*schema.Notification)(nil)).
+		Set("read_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("read_at IS NULL")
+	if len(ids) > 0 {
+		query = query.Where("id IN (?)", bun.In(ids))
+	}
+
+	if _, err := query.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return st.db.NewSelect().
+		Model((*schema.Notification)(nil)).
+		Where("user_id = ?", userID).
+		Where("read_at IS NULL").
+		Count(ctx)
+}
+
+// DeleteNotificationsBefore deletes notifications created before given time.
+// Returns the number of notifications deleted.
+func (st *Storage) DeleteNotificationsBefore(ctx context.Context, before time.Time) (int, error) {
+	logger := st.Logger(ctx, withTable(notificationTableName), withOperation("cleanup"))
+
+	res, err := st.db.NewDelete().
+		Model((*schema.Notification)(nil)).
+		Where("created_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if rows > 0 {
+		logger.Info().Msgf("Notifications deleted: %d", rows)
+	}
+
+	return int(rows), nil
+}
@@ -0,0 +1,127 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const loginAttemptsTableName = "login_attempts"
+
+// AcquireLoginAttempt counts login attempt by given key as failed in advance and locks further attempts
+// according to the rule atomically. ErrTooManyAttempts is returned along with the counter if attempts are locked.
+// Counter is incremented and locked by a single statement, so concurrent attempts can't bypass the thresholds.
+func (st *Storage) AcquireLoginAttempt(ctx context.Context, key model.LoginAttemptsKey, rule model.LoginAttemptsRule) (model.LoginAttempts, error) {
+	logger := st.Logger(ctx, withTable(loginAttemptsTableName), withOperation("acquire"))
+
+	now := time.Now()
+	windowStart := now.Add(-rule.Window)
+	dbObj := schema.NewLoginAttemptsFromCanonical(model.LoginAttempts{
+		LoginAttemptsKey: key,
+		LastFailedAt:     now,
+	})
+
+	_, err := st.db.NewInsert().
+		Model(&dbObj).
+		On("CONFLICT (kind, subject) DO NOTHING").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return model.LoginAttempts{}, err
+	}
+
+	// Counter is restarted if the previous failure is older than window
+	lockedUntil, lockedUntilArgs := lockedUntilExpr("t.failures", rule, now)
+	args := append(append([]interface{}{now}, lockedUntilArgs...), windowStart)
+	res, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("(failures, last_failed_at, locked_until) = (SELECT t.failures, ?::timestamptz, "+lockedUntil+
+			" FROM (SELECT CASE WHEN la.last_failed_at < ? THEN 1 ELSE la.failures + 1 END AS failures) AS t)", args...).
+		Where("la.kind = ?", key.Kind).
+		Where("la.subject = ?", key.Subject).
+		Where("la.locked_until IS NULL OR la.locked_until <= ?", now).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.LoginAttempts{}, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err = st.db.NewSelect().Model(&dbObj).WherePK().Scan(ctx); err != nil {
+			return model.LoginAttempts{}, err
+		}
+
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return model.LoginAttempts{}, err
+		}
+
+		return obj, pkg.ErrTooManyAttempts
+	}
+
+	// Stale counters are of no use anymore
+	_, err = st.db.NewDelete().
+		Model((*schema.LoginAttempts)(nil)).
+		Where("last_failed_at < ?", windowStart).
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Exec(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Deleting stale login attempts")
+	}
+
+	return dbObj.ToCanonical()
+}
+
+// ReleaseLoginAttempt takes back login attempt acquired by given key, the lock is lifted if it isn't reached anymore.
+func (st *Storage) ReleaseLoginAttempt(ctx context.Context, key model.LoginAttemptsKey, rule model.LoginAttemptsRule) error {
+	lockedUntil, args := lockedUntilExpr("(la.failures - 1)", rule, time.Now())
+
+	_, err := st.db.NewUpdate().
+		Model((*schema.LoginAttempts)(nil)).
+		Set("failures = la.failures - 1").
+		Set("locked_until = CASE WHEN "+lockedUntil+" IS NULL THEN NULL ELSE la.locked_until END", args...).
+		Where("la.kind = ?", key.Kind).
+		Where("la.subject = ?", key.Subject).
+		Where("la.failures > 0").
+		Exec(ctx)
+
+	return err
+}
+
+// DeleteLoginAttempts resets failed login attempts and lock by given key.
+func (st *Storage) DeleteLoginAttempts(ctx context.Context, key model.LoginAttemptsKey) error {
+	logger := st.Logger(ctx, withTable(loginAttemptsTableName), withOperation("delete"))
+
+	res, err := st.db.NewDelete().
+		Model((*schema.LoginAttempts)(nil)).
+		Where("kind = ?", key.Kind).
+		Where("subject = ?", key.Subject).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		logger.Info().Msgf("Login attempts reset for %s %s", key.Kind, key.Subject)
+	}
+
+	return nil
+}
+
+// lockedUntilExpr returns SQL expression of time attempts are locked until after given number of failures
+// (SQL expression) according to the rule, the expression is NULL if attempts aren't locked.
+func lockedUntilExpr(failures string, rule model.LoginAttemptsRule, now time.Time) (string, []interface{}) {
+	expr := fmt.Sprintf("CASE WHEN %[1]s >= ? THEN ?::timestamptz "+
+		"WHEN ? > 0 AND %[1]s >= ? THEN ?::timestamptz + make_interval(secs => LEAST(? * power(2, LEAST(%[1]s - ?, 30)), ?)) "+
+		"END", failures)
+	args := []interface{}{
+		rule.LockoutThreshold, now.Add(rule.LockoutDuration),
+		rule.DelayThreshold, rule.DelayThreshold, now, rule.DelayBase.Seconds(), rule.DelayThreshold, rule.DelayMax.Seconds(),
+	}
+
+	return expr, args
+}
@@ -0,0 +1,25 @@
+-- Sessions table (refresh tokens)
+CREATE TABLE sessions
+(
+    "id"                 UUID                 DEFAULT uuid_generate_v4(),
+    "user_id"            UUID        NOT NULL,
+    "refresh_token_hash" VARCHAR(64) NOT NULL,
+    "expires_at"         TIMESTAMPTZ NOT NULL,
+    "revoked_at"         TIMESTAMPTZ,
+    "created_at"         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at"         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("refresh_token_hash")
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions ("user_id");
+
+-- Revoked access tokens table
+CREATE TABLE revoked_tokens
+(
+    "jti"        VARCHAR(64) NOT NULL,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY ("jti")
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens ("expires_at");
@@ -0,0 +1,5 @@
+-- Case-insensitive logins uniqueness
+-- Migration fails if there are active users with logins differing in case only: they must be resolved manually
+DROP INDEX IF EXISTS users_login_idx;
+
+CREATE UNIQUE INDEX users_login_lower_idx ON users (lower(login)) WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+-- Failed login attempts table (tracked per login and per client IP)
+CREATE TABLE login_attempts
+(
+    "kind"           VARCHAR(10)  NOT NULL,
+    "subject"        VARCHAR(100) NOT NULL,
+    "failures"       INT          NOT NULL DEFAULT 0,
+    "last_failed_at" TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    "locked_until"   TIMESTAMPTZ,
+    PRIMARY KEY ("kind", "subject")
+);
+
+CREATE INDEX login_attempts_last_failed_at_idx ON login_attempts (last_failed_at);
@@ -0,0 +1,14 @@
+-- Password reset tokens table
+CREATE TABLE password_reset_tokens
+(
+    "id"         UUID                 DEFAULT uuid_generate_v4(),
+    "user_id"    UUID        NOT NULL,
+    "token_hash" VARCHAR(64) NOT NULL,
+    "expires_at" TIMESTAMPTZ NOT NULL,
+    "used_at"    TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("token_hash")
+);
+
+CREATE INDEX password_reset_tokens_user_id_idx ON password_reset_tokens ("user_id");
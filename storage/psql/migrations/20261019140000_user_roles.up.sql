@@ -0,0 +1,4 @@
+-- Users roles
+ALTER TABLE users
+    ADD COLUMN "role" VARCHAR(16) NOT NULL DEFAULT 'user'
+        CONSTRAINT users_role_check CHECK ("role" IN ('user', 'support', 'admin'));
@@ -0,0 +1,22 @@
+-- TOTP two-factor authentication table
+CREATE TABLE user_totp
+(
+    "user_id"        UUID        NOT NULL,
+    "secret"         VARCHAR(64) NOT NULL,
+    "last_used_step" BIGINT      NOT NULL DEFAULT 0,
+    "confirmed_at"   TIMESTAMPTZ,
+    "created_at"     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("user_id")
+);
+
+-- Two-factor authentication recovery codes table
+CREATE TABLE totp_recovery_codes
+(
+    "id"         UUID                 DEFAULT uuid_generate_v4(),
+    "user_id"    UUID        NOT NULL,
+    "code_hash"  VARCHAR(64) NOT NULL,
+    "used_at"    TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("user_id", "code_hash")
+);
@@ -0,0 +1,4 @@
+-- Sessions client data
+ALTER TABLE sessions
+    ADD COLUMN "ip"         VARCHAR(64)  NOT NULL DEFAULT '',
+    ADD COLUMN "user_agent" VARCHAR(512) NOT NULL DEFAULT '';
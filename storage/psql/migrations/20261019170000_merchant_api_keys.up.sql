@@ -0,0 +1,15 @@
+-- Merchant API keys table
+CREATE TABLE merchant_api_keys
+(
+    "id"           UUID                   DEFAULT uuid_generate_v4(),
+    "name"         VARCHAR(100)  NOT NULL,
+    "key_prefix"   VARCHAR(16)   NOT NULL,
+    "key_hash"     VARCHAR(64)   NOT NULL,
+    "scopes"       VARCHAR(32)[] NOT NULL,
+    "expires_at"   TIMESTAMPTZ,
+    "revoked_at"   TIMESTAMPTZ,
+    "last_used_at" TIMESTAMPTZ,
+    "created_at"   TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("key_hash")
+);
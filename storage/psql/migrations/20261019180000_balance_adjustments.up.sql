@@ -0,0 +1,7 @@
+-- Manual balance adjustments: transactions without order made by operator with a reason
+ALTER TABLE transactions
+    ALTER COLUMN "order" DROP NOT NULL,
+    ADD COLUMN "operator_id" UUID,
+    ADD COLUMN "reason"      VARCHAR(500),
+    ADD CONSTRAINT transactions_adjustment_check
+        CHECK (("operator_id" IS NULL) = ("reason" IS NULL) AND ("operator_id" IS NOT NULL OR "order" IS NOT NULL));
@@ -0,0 +1,24 @@
+-- Audit log of balance-affecting operations (hash chained)
+CREATE TABLE audit_log
+(
+    "seq"            BIGINT       NOT NULL,
+    "action"         VARCHAR(32)  NOT NULL,
+    "actor_type"     VARCHAR(16)  NOT NULL,
+    "actor_id"       VARCHAR(64)  NOT NULL,
+    "request_id"     VARCHAR(128) NOT NULL,
+    "user_id"        UUID         NOT NULL,
+    "reference"      VARCHAR(64)  NOT NULL,
+    "amount"         BIGINT       NOT NULL,
+    "balance_before" BIGINT       NOT NULL,
+    "balance_after"  BIGINT       NOT NULL,
+    "prev_hash"      VARCHAR(64)  NOT NULL,
+    "hash"           VARCHAR(64)  NOT NULL,
+    "created_at"     TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY ("seq")
+);
+
+CREATE INDEX audit_log_user_id_idx ON audit_log ("user_id");
+
+-- Audit log is append-only
+CREATE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING;
+CREATE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING;
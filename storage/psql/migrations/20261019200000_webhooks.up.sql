@@ -0,0 +1,32 @@
+-- Transactional outbox of domain events
+CREATE TABLE outbox_events
+(
+    "id"                   UUID                 DEFAULT uuid_generate_v4(),
+    "type"                 VARCHAR(64) NOT NULL,
+    "payload"              JSONB       NOT NULL,
+    "created_at"           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "webhooks_enqueued_at" TIMESTAMPTZ,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX outbox_events_webhooks_pending_idx ON outbox_events ("created_at") WHERE webhooks_enqueued_at IS NULL;
+
+-- Webhook deliveries (one per event and subscription)
+CREATE TABLE webhook_deliveries
+(
+    "id"               UUID                 DEFAULT uuid_generate_v4(),
+    "event_id"         UUID        NOT NULL REFERENCES outbox_events ("id"),
+    "webhook"          VARCHAR(64) NOT NULL,
+    "status"           VARCHAR(16) NOT NULL DEFAULT 'pending',
+    "attempts"         INT         NOT NULL DEFAULT 0,
+    "next_attempt_at"  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "last_status_code" INT,
+    "last_error"       TEXT,
+    "delivered_at"     TIMESTAMPTZ,
+    "created_at"       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at"       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("event_id", "webhook")
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries ("next_attempt_at") WHERE status = 'pending';
@@ -0,0 +1,5 @@
+-- Outbox events publishing to the event publisher
+ALTER TABLE outbox_events
+    ADD COLUMN "published_at" TIMESTAMPTZ;
+
+CREATE INDEX outbox_events_unpublished_idx ON outbox_events ("created_at") WHERE published_at IS NULL;
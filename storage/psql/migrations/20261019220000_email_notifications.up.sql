@@ -0,0 +1,44 @@
+-- User email address notifications are sent to
+ALTER TABLE users
+    ADD COLUMN "email" VARCHAR(254);
+
+-- Per notification type user opt-in preferences
+CREATE TABLE notification_preferences
+(
+    "user_id" UUID        NOT NULL REFERENCES users ("id"),
+    "type"    VARCHAR(32) NOT NULL,
+    "email"   BOOLEAN     NOT NULL DEFAULT false,
+    PRIMARY KEY ("user_id", "type")
+);
+
+-- Outbox events turning into notifications, events written before notifications were introduced are skipped
+ALTER TABLE outbox_events
+    ADD COLUMN "notifications_enqueued_at" TIMESTAMPTZ;
+
+UPDATE outbox_events
+SET notifications_enqueued_at = now();
+
+CREATE INDEX outbox_events_notifications_pending_idx ON outbox_events ("created_at") WHERE notifications_enqueued_at IS NULL;
+
+-- Email notifications queue (one per outbox event at most)
+CREATE TABLE email_messages
+(
+    "id"              UUID                 DEFAULT uuid_generate_v4(),
+    "event_id"        UUID        NOT NULL REFERENCES outbox_events ("id"),
+    "user_id"         UUID        NOT NULL REFERENCES users ("id"),
+    "type"            VARCHAR(32) NOT NULL,
+    "recipient"       VARCHAR(254) NOT NULL,
+    "subject"         TEXT        NOT NULL,
+    "body"            TEXT        NOT NULL,
+    "status"          VARCHAR(16) NOT NULL DEFAULT 'pending',
+    "attempts"        INT         NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "last_error"      TEXT,
+    "sent_at"         TIMESTAMPTZ,
+    "created_at"      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    "updated_at"      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("event_id")
+);
+
+CREATE INDEX email_messages_due_idx ON email_messages ("next_attempt_at") WHERE status = 'pending';
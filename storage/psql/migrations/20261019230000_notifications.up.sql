@@ -0,0 +1,27 @@
+-- In-app notifications inbox (one per outbox event at most)
+CREATE TABLE notifications
+(
+    "id"         UUID                 DEFAULT uuid_generate_v4(),
+    "user_id"    UUID        NOT NULL REFERENCES users ("id"),
+    "event_id"   UUID        NOT NULL REFERENCES outbox_events ("id"),
+    "type"       VARCHAR(64) NOT NULL,
+    "message"    TEXT        NOT NULL,
+    "data"       JSONB       NOT NULL,
+    "read_at"    TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("event_id")
+);
+
+CREATE INDEX notifications_user_idx ON notifications ("user_id", "created_at" DESC);
+CREATE INDEX notifications_unread_idx ON notifications ("user_id") WHERE read_at IS NULL;
+CREATE INDEX notifications_created_at_idx ON notifications ("created_at");
+
+-- Outbox events turning into inbox notifications, events written before the inbox was introduced are skipped
+ALTER TABLE outbox_events
+    ADD COLUMN "inbox_enqueued_at" TIMESTAMPTZ;
+
+UPDATE outbox_events
+SET inbox_enqueued_at = now();
+
+CREATE INDEX outbox_events_inbox_pending_idx ON outbox_events ("created_at") WHERE inbox_enqueued_at IS NULL;
@@ -0,0 +1,6 @@
+-- Optional user profile data
+ALTER TABLE users
+    ADD COLUMN "display_name" VARCHAR(64),
+    ADD COLUMN "phone"        VARCHAR(16),
+    ADD COLUMN "birthday"     DATE,
+    ADD COLUMN "language"     VARCHAR(35);
@@ -0,0 +1,3 @@
+-- TOTP secrets are stored encrypted (version prefix, nonce and ciphertext are longer than plaintext)
+ALTER TABLE user_totp
+    ALTER COLUMN "secret" TYPE VARCHAR(255);
@@ -0,0 +1,20 @@
+-- Logins of users registered before login normalization was introduced are trimmed and NFKC normalized
+-- the same way new ones are (requires PostgreSQL 13+ with UTF8 database encoding).
+-- Migration fails if there are active users with logins equal after normalization (case-insensitively).
+-- Such collisions must be resolved manually beforehand, e.g. by renaming all but the oldest colliding user:
+--
+--   SELECT lower(normalize(regexp_replace(login, '^[[:space:]]+|[[:space:]]+$', '', 'g'), NFKC)) AS normalized,
+--          array_agg(id ORDER BY created_at) AS user_ids
+--   FROM users
+--   WHERE deleted_at IS NULL
+--   GROUP BY 1
+--   HAVING count(*) > 1;
+--
+--   UPDATE users SET login = login || '-' || left(id::text, 8) WHERE id = '<colliding user id>';
+UPDATE users
+SET login = normalize(regexp_replace(login, '^[[:space:]]+|[[:space:]]+$', '', 'g'), NFKC)
+WHERE login <> normalize(regexp_replace(login, '^[[:space:]]+|[[:space:]]+$', '', 'g'), NFKC);
+
+DROP INDEX IF EXISTS users_login_lower_idx;
+
+CREATE UNIQUE INDEX users_login_normalized_idx ON users (lower(normalize(login, NFKC))) WHERE deleted_at IS NULL;
@@ -0,0 +1,39 @@
+-- Outbox events sequence: events are consumed in the order of it instead of created_at
+-- (events written within the same transaction have the same created_at).
+-- Existing events are numbered by created_at, ties are broken by id.
+CREATE SEQUENCE outbox_events_seq_seq AS BIGINT;
+
+ALTER TABLE outbox_events
+    ADD COLUMN "seq" BIGINT;
+
+UPDATE outbox_events oe
+SET seq = ordered.seq
+FROM (SELECT id, row_number() OVER (ORDER BY created_at, id) AS seq FROM outbox_events) AS ordered
+WHERE oe.id = ordered.id;
+
+SELECT setval('outbox_events_seq_seq', COALESCE(MAX(seq), 0) + 1, false) FROM outbox_events;
+
+ALTER TABLE outbox_events
+    ALTER COLUMN "seq" SET DEFAULT nextval('outbox_events_seq_seq'),
+    ALTER COLUMN "seq" SET NOT NULL;
+
+ALTER SEQUENCE outbox_events_seq_seq OWNED BY outbox_events.seq;
+
+CREATE UNIQUE INDEX outbox_events_seq_idx ON outbox_events ("seq");
+
+-- Leased publishing attempts, events failed to be published max attempts times become dead
+ALTER TABLE outbox_events
+    ADD COLUMN "publish_attempts"        INT         NOT NULL DEFAULT 0,
+    ADD COLUMN "publish_next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN "publish_last_error"      TEXT,
+    ADD COLUMN "publish_dead_at"         TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_events_unpublished_idx;
+DROP INDEX IF EXISTS outbox_events_webhooks_pending_idx;
+DROP INDEX IF EXISTS outbox_events_notifications_pending_idx;
+DROP INDEX IF EXISTS outbox_events_inbox_pending_idx;
+
+CREATE INDEX outbox_events_unpublished_idx ON outbox_events ("seq") WHERE published_at IS NULL AND publish_dead_at IS NULL;
+CREATE INDEX outbox_events_webhooks_pending_idx ON outbox_events ("seq") WHERE webhooks_enqueued_at IS NULL;
+CREATE INDEX outbox_events_notifications_pending_idx ON outbox_events ("seq") WHERE notifications_enqueued_at IS NULL;
+CREATE INDEX outbox_events_inbox_pending_idx ON outbox_events ("seq") WHERE inbox_enqueued_at IS NULL;
@@ -0,0 +1,6 @@
+-- Manual adjustment reason is written by operators for internal use and is not shown to users,
+-- so it is removed from inbox notifications written before
+UPDATE notifications
+SET message = regexp_replace(message, ' \(.*\), balance is ([0-9.-]+)$', ', balance is \1'),
+    data    = data - 'reason'
+WHERE type = 'points.adjusted';
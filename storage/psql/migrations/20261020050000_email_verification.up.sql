@@ -0,0 +1,19 @@
+-- Email addresses are set once verified with a token sent to them.
+-- Addresses set before are kept unverified, so password reset tokens aren't sent to them.
+ALTER TABLE users
+    ADD COLUMN "email_verified_at" TIMESTAMPTZ;
+
+CREATE TABLE email_verification_tokens
+(
+    "id"         UUID                  DEFAULT uuid_generate_v4(),
+    "user_id"    UUID         NOT NULL,
+    "email"      VARCHAR(254) NOT NULL,
+    "token_hash" VARCHAR(64)  NOT NULL,
+    "expires_at" TIMESTAMPTZ  NOT NULL,
+    "used_at"    TIMESTAMPTZ,
+    "created_at" TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY ("id"),
+    UNIQUE ("token_hash")
+);
+
+CREATE INDEX email_verification_tokens_user_id_idx ON email_verification_tokens ("user_id");
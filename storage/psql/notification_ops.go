@@ -0,0 +1,250 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const (
+	notificationPreferenceTableName = "notification_preference"
+	emailMessageTableName           = "email_message"
+)
+
+// GetNotificationSettings gets user email (whether it is verified) and stored notification preferences.
+func (st *Storage) GetNotificationSettings(ctx context.Context, userID uuid.UUID) (model.NotificationSettings, error) {
+	dbUser := schema.User{ID: userID}
+	err := st.db.NewSelect().
+		Model(&dbUser).
+		Column("email", "email_verified_at").
+		WherePK().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.NotificationSettings{}, pkg.ErrNotFound
+		}
+		return model.NotificationSettings{}, err
+	}
+
+	var dbObjs []schema.NotificationPreference
+	err = st.db.NewSelect().
+		Model(&dbObjs).
+		Where("user_id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		return model.NotificationSettings{}, err
+	}
+
+	obj := model.NotificationSettings{
+		Email:         dbUser.Email,
+		EmailVerified: !dbUser.EmailVerifiedAt.IsZero(),
+		EmailEnabled:  make(map[model.NotificationType]bool, len(dbObjs)),
+	}
+	for _, dbObj := range dbObjs {
+		obj.EmailEnabled[model.NotificationType(dbObj.Type)] = dbObj.Email
+	}
+
+	return obj, nil
+}
+
+// SetNotificationSettings sets given notification preferences, preferences of types not given are kept.
+// User email is changed with email verification only.
+func (st *Storage) SetNotificationSettings(ctx context.Context, userID uuid.UUID, obj model.NotificationSettings) error {
+	logger := st.Logger(ctx, withTable(notificationPreferenceTableName), withOperation("set"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model((*schema.User)(nil)).
+			Set("updated_at = NOW()").
+			Where("id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pkg.ErrNotFound
+		}
+
+		dbObjs := schema.NewNotificationPreferencesFromCanonical(userID, obj)
+		if len(dbObjs) == 0 {
+			return nil
+		}
+
+		_, err = tx.NewInsert().
+			Model(&dbObjs).
+			On("CONFLICT (user_id, type) DO UPDATE").
+			Set("email = EXCLUDED.email").
+			Returning("NULL").
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("User notification settings set %s", userID)
+
+	return nil
+}
+
+// deleteUserNotifications deletes user notification preferences and inbox and cancels pending user emails.
+func deleteUserNotifications(ctx context.Context, db bun.IDB, userID uuid.UUID) error {
+	_, err := db.NewDelete().
+		Model((*schema.NotificationPreference)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDelete().
+		Model((*schema.Notification)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewUpdate().
+		Model((*schema.EmailMessage)(nil)).
+		Set("status = ?", model.EmailFailed).
+		Set("last_error = 'user deleted'").
+		Set("updated_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("status = ?", model.EmailPending).
+		Exec(ctx)
+
+	return err
+}
+
+// GetPendingNotificationEvents gets outbox events not yet turned into notifications, oldest first.
+func (st *Storage) GetPendingNotificationEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	var dbObjs schema.OutboxEvents
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("notifications_enqueued_at IS NULL").
+		Order("seq").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// EnqueueEmails adds given emails to the sending queue and marks given outbox events notified.
+// An event makes at most one email, so events handled concurrently don't make duplicates.
+// Returns the number of emails enqueued.
+func (st *Storage) EnqueueEmails(ctx context.Context, eventIDs []uuid.UUID, objs []model.Email) (int, error) {
+	logger := st.Logger(ctx, withTable(emailMessageTableName), withOperation("enqueue"))
+
+	var created int
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(objs) > 0 {
+			dbObjs := make([]schema.EmailMessage, 0, len(objs))
+			for _, obj := range objs {
+				dbObjs = append(dbObjs, schema.NewEmailMessageFromCanonical(obj))
+			}
+
+			res, err := tx.NewInsert().
+				Model(&dbObjs).
+				On("CONFLICT (event_id) DO NOTHING").
+				Returning("NULL").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			created = int(rows)
+		}
+
+		if len(eventIDs) == 0 {
+			return nil
+		}
+
+		_, err := tx.NewUpdate().
+			Model((*schema.OutboxEvent)(nil)).
+			Set("notifications_enqueued_at = NOW()").
+			Where("id IN (?)", bun.In(eventIDs)).
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if created > 0 {
+		logger.Debug().Msgf("Emails enqueued: %d", created)
+	}
+
+	return created, nil
+}
+
+// ClaimEmails gets pending emails due for a sending attempt.
+// Claimed emails are postponed by lease, so concurrent dispatchers don't pick them up meanwhile.
+func (st *Storage) ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]model.Email, error) {
+	var dbObjs schema.EmailMessages
+
+	dueQuery := st.db.NewSelect().
+		Model((*schema.EmailMessage)(nil)).
+		Column("id").
+		Where("status = ?", model.EmailPending).
+		Where("next_attempt_at <= NOW()").
+		Order("next_attempt_at").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	_, err := st.db.NewUpdate().
+		Model(&dbObjs).
+		Set("next_attempt_at = ?", time.Now().Add(lease)).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", dueQuery).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// UpdateEmail saves email sending attempt result.
+func (st *Storage) UpdateEmail(ctx context.Context, obj model.Email) error {
+	dbObj := schema.EmailMessage{
+		ID:            obj.ID,
+		Status:        string(obj.Status),
+		Attempts:      obj.Attempts,
+		NextAttemptAt: obj.NextAttemptAt,
+		LastError:     obj.LastError,
+		SentAt:        obj.SentAt,
+		UpdatedAt:     time.Now(),
+	}
+
+	_, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Column("status", "attempts", "next_attempt_at", "last_error", "sent_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/uptrace/bun"
 
 	"github.com/vstdy/gophermart/model"
 	"github.com/vstdy/gophermart/pkg"
@@ -12,16 +13,28 @@ import (
 
 const orderTableName = "order"
 
-// AddOrder adds given order to storage.
+// AddOrder adds given order to storage and writes order.uploaded event to the outbox.
 func (st *Storage) AddOrder(ctx context.Context, obj model.Order) (model.Order, error) {
 	dbObj := schema.NewOrderFromCanonical(obj)
 
-	_, err := st.db.NewInsert().
-		Model(&dbObj).
-		On("CONFLICT (\"number\") DO UPDATE").
-		Set("updated_at=NOW()").
-		Returning("*, uploaded_at <> updated_at AS updated").
-		Exec(ctx)
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(&dbObj).
+			On("CONFLICT (\"number\") DO UPDATE").
+			Set("updated_at=NOW()").
+			Returning("*, uploaded_at <> updated_at AS updated").
+			Exec(ctx)
+		if err != nil || dbObj.Updated {
+			return err
+		}
+
+		return addOutboxEvent(ctx, tx, model.EventTypeOrderUploaded, model.OrderUploadedPayload{
+			Order:      dbObj.Number,
+			UserID:     dbObj.UserID,
+			Status:     dbObj.Status,
+			UploadedAt: dbObj.UploadedAt,
+		})
+	})
 	if err != nil {
 		return model.Order{}, err
 	}
@@ -39,23 +52,60 @@ func (st *Storage) AddOrder(ctx context.Context, obj model.Order) (model.Order,
 }
 
 // UpdateOrders updates given orders.
+// Changes are written to the outbox as order.status_changed events,
+// orders becoming processed - also as order.processed events.
 func (st *Storage) UpdateOrders(ctx context.Context, objs []model.Order) error {
 	dbObjs := schema.NewOrdersFromCanonical(objs)
 	values := st.db.NewValues(&dbObjs)
 
-	_, err := st.db.NewUpdate().
-		With("_data", values).
-		Model(&dbObjs).
-		TableExpr("_data").
-		Set("status = _data.status").
-		Set("accrual = _data.accrual").
-		Where("o.number = _data.number").
-		Exec(ctx)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Joined prev row keeps values before the update
+		var updObjs schema.Orders
+		_, err := tx.NewUpdate().
+			With("_data", values).
+			Model((*schema.Order)(nil)).
+			TableExpr("_data").
+			TableExpr("orders AS prev").
+			Set("status = _data.status").
+			Set("accrual = _data.accrual").
+			Where("o.number = _data.number").
+			Where("prev.id = o.id").
+			Where("(o.status, o.accrual) IS DISTINCT FROM (_data.status, _data.accrual)").
+			Returning("o.*, prev.status AS previous_status").
+			Exec(ctx, &updObjs)
+		if err != nil {
+			return err
+		}
+
+		for _, dbObj := range updObjs {
+			err = addOutboxEvent(ctx, tx, model.EventTypeOrderStatusChanged, model.OrderStatusChangedPayload{
+				Order:          dbObj.Number,
+				UserID:         dbObj.UserID,
+				PreviousStatus: dbObj.PreviousStatus,
+				Status:         dbObj.Status,
+				Accrual:        float32(dbObj.Accrual) / 100,
+			})
+			if err != nil {
+				return err
+			}
+
+			if dbObj.Status != model.OrderStatusProcessed.String() {
+				continue
+			}
+
+			err = addOutboxEvent(ctx, tx, model.EventTypeOrderProcessed, model.OrderProcessedPayload{
+				Order:   dbObj.Number,
+				UserID:  dbObj.UserID,
+				Status:  dbObj.Status,
+				Accrual: float32(dbObj.Accrual) / 100,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 // GetStatusNewOrders gets orders with status NEW.
@@ -103,3 +153,34 @@ func (st *Storage) GetOrders(ctx context.Context, userID uuid.UUID) ([]model.Ord
 
 	return objs, nil
 }
+
+// GetPendingOrdersCount gets number of not yet processed orders by status.
+func (st *Storage) GetPendingOrdersCount(ctx context.Context) (map[model.OrderStatus]int, error) {
+	var rows []struct {
+		Status string `bun:"status"`
+		Count  int    `bun:"count"`
+	}
+
+	err := st.db.NewSelect().
+		Model((*schema.Order)(nil)).
+		ColumnExpr("status, count(*) AS count").
+		Where("status IN (?)", bun.In([]string{
+			model.OrderStatusNew.String(),
+			model.OrderStatusProcessing.String(),
+		})).
+		Group("status").
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[model.OrderStatus]int{
+		model.OrderStatusNew:        0,
+		model.OrderStatusProcessing: 0,
+	}
+	for _, row := range rows {
+		counts[model.NewOrderStatusFromStr(row.Status)] = row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,127 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const outboxTableName = "outbox_event"
+
+// addOutboxEvent writes domain event with JSON encoded payload to the outbox.
+// Expected to be called within the transaction making the change the event is about,
+// so events of rolled back changes are never published.
+func addOutboxEvent(ctx context.Context, db bun.IDB, eventType model.EventType, payload interface{}) error {
+	dbObj, err := schema.NewOutboxEvent(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewInsert().
+		Model(&dbObj).
+		Exec(ctx)
+
+	return err
+}
+
+// ClaimUnpublishedEvents gets outbox events neither published nor dead and due for an attempt, oldest first.
+// Claimed events are postponed by lease, so concurrent relays don't pick them up meanwhile.
+func (st *Storage) ClaimUnpublishedEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	var ids []uuid.UUID
+
+	dueQuery := st.db.NewSelect().
+		Model((*schema.OutboxEvent)(nil)).
+		Column("id").
+		Where("published_at IS NULL").
+		Where("publish_dead_at IS NULL").
+		Where("publish_next_attempt_at <= NOW()").
+		Order("seq").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	_, err := st.db.NewUpdate().
+		Model((*schema.OutboxEvent)(nil)).
+		Set("publish_next_attempt_at = ?", time.Now().Add(lease)).
+		Where("id IN (?)", dueQuery).
+		Returning("id").
+		Exec(ctx, &ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var dbObjs schema.OutboxEvents
+	err = st.db.NewSelect().
+		Model(&dbObjs).
+		Where("id IN (?)", bun.In(ids)).
+		Order("seq").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// MarkEventsPublished marks given outbox events published.
+func (st *Storage) MarkEventsPublished(ctx context.Context, ids []uuid.UUID) error {
+	logger := st.Logger(ctx, withTable(outboxTableName), withOperation("publish"))
+
+	_, err := st.db.NewUpdate().
+		Model((*schema.OutboxEvent)(nil)).
+		Set("published_at = NOW()").
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug().Msgf("Events published: %d", len(ids))
+
+	return nil
+}
+
+// FailEventPublishing saves failed publishing attempt of outbox event and makes it due for the next attempt.
+// Event becomes dead once it has been attempted maxAttempts times. Returns whether the event is dead.
+func (st *Storage) FailEventPublishing(ctx context.Context, id uuid.UUID, reason string, maxAttempts int) (bool, error) {
+	logger := st.Logger(ctx, withTable(outboxTableName), withOperation("publish"))
+
+	var dbObj schema.OutboxEvent
+	_, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("publish_attempts = publish_attempts + 1").
+		Set("publish_last_error = ?", reason).
+		Set("publish_next_attempt_at = NOW()").
+		Set("publish_dead_at = CASE WHEN publish_attempts + 1 >= ? THEN NOW() END", maxAttempts).
+		Where("id = ?", id).
+		Returning("publish_attempts, publish_dead_at").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	dead := !dbObj.PublishDeadAt.IsZero()
+	if dead {
+		logger.Warn().Msgf("Event %s is dead after %d publishing attempts", id, dbObj.PublishAttempts)
+	}
+
+	return dead, nil
+}
+
+// ReleaseEvents makes given claimed outbox events due for an attempt at once.
+func (st *Storage) ReleaseEvents(ctx context.Context, ids []uuid.UUID) error {
+	_, err := st.db.NewUpdate().
+		Model((*schema.OutboxEvent)(nil)).
+		Set("publish_next_attempt_at = NOW()").
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+
+	return err
+}
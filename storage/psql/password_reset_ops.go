@@ -0,0 +1,103 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const passwordResetTokenTableName = "password_reset_token"
+
+// CreatePasswordResetToken adds given password reset token to storage,
+// previously issued user tokens are deleted.
+func (st *Storage) CreatePasswordResetToken(ctx context.Context, obj model.PasswordResetToken) (model.PasswordResetToken, error) {
+	logger := st.Logger(ctx, withTable(passwordResetTokenTableName), withOperation("insert"))
+
+	dbObj := schema.NewPasswordResetTokenFromCanonical(obj)
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model((*schema.PasswordResetToken)(nil)).
+			Where("user_id = ?", dbObj.UserID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewInsert().
+			Model(&dbObj).
+			Returning("*").
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return model.PasswordResetToken{}, err
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.PasswordResetToken{}, err
+	}
+	addedObj.Token = obj.Token
+
+	logger.Info().Msgf("Password reset token created for user %s", addedObj.UserID)
+
+	return addedObj, nil
+}
+
+// ResetPassword consumes password reset token, sets user password and revokes user sessions.
+func (st *Storage) ResetPassword(ctx context.Context, token, password string) (model.User, error) {
+	logger := st.Logger(ctx, withTable(passwordResetTokenTableName), withOperation("reset_password"))
+
+	var dbUser schema.User
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var dbObj schema.PasswordResetToken
+		res, err := tx.NewUpdate().
+			Model(&dbObj).
+			Set("used_at = NOW()").
+			Where("token_hash = ?", schema.HashToken(token)).
+			Where("used_at IS NULL").
+			Where("expires_at > NOW()").
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return pkg.ErrInvalidToken
+		}
+
+		dbUser.ID = dbObj.UserID
+		err = tx.NewSelect().
+			Model(&dbUser).
+			WherePK().
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.ErrInvalidToken
+			}
+			return err
+		}
+
+		return st.setUserPassword(ctx, tx, dbUser.ID, password)
+	})
+	if err != nil {
+		return model.User{}, err
+	}
+
+	obj, err := dbUser.ToCanonical()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	logger.Info().Msgf("User password reset %s", obj.ID)
+
+	return obj, nil
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// APIKey keeps merchant API key data.
+type APIKey struct {
+	bun.BaseModel `bun:"merchant_api_keys,alias:mak"`
+	ID            uuid.UUID `bun:"id,pk,type:uuid"`
+	Name          string    `bun:"name,notnull"`
+	KeyPrefix     string    `bun:"key_prefix,notnull"`
+	KeyHash       string    `bun:"key_hash,unique,notnull"`
+	Scopes        []string  `bun:"scopes,array,notnull"`
+	ExpiresAt     time.Time `bun:"expires_at,nullzero"`
+	RevokedAt     time.Time `bun:"revoked_at,nullzero"`
+	LastUsedAt    time.Time `bun:"last_used_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// NewAPIKeyFromCanonical creates a new APIKey DB object from canonical model.
+func NewAPIKeyFromCanonical(obj model.APIKey) APIKey {
+	scopes := make([]string, 0, len(obj.Scopes))
+	for _, scope := range obj.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return APIKey{
+		ID:         obj.ID,
+		Name:       obj.Name,
+		KeyPrefix:  obj.Prefix,
+		KeyHash:    HashToken(obj.Key),
+		Scopes:     scopes,
+		ExpiresAt:  obj.ExpiresAt,
+		RevokedAt:  obj.RevokedAt,
+		LastUsedAt: obj.LastUsedAt,
+		CreatedAt:  obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (k APIKey) ToCanonical() (model.APIKey, error) {
+	scopes := make([]model.APIKeyScope, 0, len(k.Scopes))
+	for _, scope := range k.Scopes {
+		scopes = append(scopes, model.APIKeyScope(scope))
+	}
+
+	return model.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.KeyPrefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// AuditEntry keeps audit log entry data, amounts are in hundredths.
+type AuditEntry struct {
+	bun.BaseModel `bun:"audit_log,alias:al"`
+	Seq           int64     `bun:"seq,pk"`
+	Action        string    `bun:"action,notnull"`
+	ActorType     string    `bun:"actor_type,notnull"`
+	ActorID       string    `bun:"actor_id,notnull"`
+	RequestID     string    `bun:"request_id,notnull"`
+	UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
+	Reference     string    `bun:"reference,notnull"`
+	Amount        int64     `bun:"amount,notnull"`
+	BalanceBefore int64     `bun:"balance_before,notnull"`
+	BalanceAfter  int64     `bun:"balance_after,notnull"`
+	PrevHash      string    `bun:"prev_hash,notnull"`
+	Hash          string    `bun:"hash,notnull"`
+	CreatedAt     time.Time `bun:"created_at,notnull"`
+}
+
+// ComputeHash returns SHA-256 hash of the entry data chained to the previous entry hash.
+// Data is serialized as JSON array, so field boundaries are unambiguous.
+func (e AuditEntry) ComputeHash() string {
+	data, err := json.Marshal([]interface{}{
+		e.Seq,
+		e.Action,
+		e.ActorType,
+		e.ActorID,
+		e.RequestID,
+		e.UserID.String(),
+		e.Reference,
+		e.Amount,
+		e.BalanceBefore,
+		e.BalanceAfter,
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		// Marshalling of strings and numbers never fails
+		panic(fmt.Errorf("marshalling audit entry: %w", err))
+	}
+
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (e AuditEntry) ToCanonical() (model.AuditEntry, error) {
+	return model.AuditEntry{
+		Seq:           e.Seq,
+		Action:        model.AuditAction(e.Action),
+		Actor:         model.Actor{Type: model.ActorType(e.ActorType), ID: e.ActorID},
+		RequestID:     e.RequestID,
+		UserID:        e.UserID,
+		Reference:     e.Reference,
+		Amount:        float32(e.Amount) / 100,
+		BalanceBefore: float32(e.BalanceBefore) / 100,
+		BalanceAfter:  float32(e.BalanceAfter) / 100,
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     e.CreatedAt,
+	}, nil
+}
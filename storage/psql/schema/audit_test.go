@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditEntryComputeHash(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 123456000, time.UTC)
+	entry := AuditEntry{
+		Seq:           2,
+		Action:        "withdrawal",
+		ActorType:     "user",
+		ActorID:       "operator",
+		RequestID:     "request",
+		UserID:        uuid.MustParse("7f8b2f4e-3b0a-4c1e-9a51-0d3c1e2a4b5c"),
+		Reference:     "2377225624",
+		Amount:        -1050,
+		BalanceBefore: 5000,
+		BalanceAfter:  3950,
+		PrevHash:      "previous",
+		CreatedAt:     createdAt,
+	}
+
+	hash := entry.ComputeHash()
+	if len(hash) != 64 {
+		t.Fatalf("hash: got %q, want hex encoded SHA-256", hash)
+	}
+
+	local := entry
+	local.CreatedAt = createdAt.In(time.FixedZone("UTC+3", 3*60*60))
+	if local.ComputeHash() != hash {
+		t.Error("hash depends on created_at time zone")
+	}
+
+	stored := entry
+	stored.Hash = hash
+	if stored.ComputeHash() != hash {
+		t.Error("hash depends on the stored hash")
+	}
+
+	altered := map[string]func(*AuditEntry){
+		"seq":            func(e *AuditEntry) { e.Seq++ },
+		"action":         func(e *AuditEntry) { e.Action = "accrual" },
+		"actor":          func(e *AuditEntry) { e.ActorID = "someone" },
+		"request":        func(e *AuditEntry) { e.RequestID = "" },
+		"user":           func(e *AuditEntry) { e.UserID = uuid.Nil },
+		"reference":      func(e *AuditEntry) { e.Reference = "0" },
+		"amount":         func(e *AuditEntry) { e.Amount = -1 },
+		"balance before": func(e *AuditEntry) { e.BalanceBefore++ },
+		"balance after":  func(e *AuditEntry) { e.BalanceAfter++ },
+		"previous hash":  func(e *AuditEntry) { e.PrevHash = "" },
+		"created at":     func(e *AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+		// Field boundaries must be kept: "user"+"operator" vs "useroperator"+""
+		"boundaries": func(e *AuditEntry) { e.ActorType, e.ActorID = "useroperator", "" },
+	}
+	for name, modify := range altered {
+		other := entry
+		modify(&other)
+		if other.ComputeHash() == hash {
+			t.Errorf("%s: alteration not detected", name)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// EmailVerificationToken keeps email verification token data.
+type EmailVerificationToken struct {
+	bun.BaseModel `bun:"email_verification_tokens,alias:evt"`
+	ID            uuid.UUID `bun:"id,pk,type:uuid"`
+	UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
+	Email         string    `bun:"email,notnull"`
+	TokenHash     string    `bun:"token_hash,unique,notnull"`
+	ExpiresAt     time.Time `bun:"expires_at,notnull"`
+	UsedAt        time.Time `bun:"used_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// NewEmailVerificationTokenFromCanonical creates a new EmailVerificationToken DB object from canonical model.
+func NewEmailVerificationTokenFromCanonical(obj model.EmailVerificationToken) EmailVerificationToken {
+	return EmailVerificationToken{
+		ID:        obj.ID,
+		UserID:    obj.UserID,
+		Email:     obj.Email,
+		TokenHash: HashToken(obj.Token),
+		ExpiresAt: obj.ExpiresAt,
+		UsedAt:    obj.UsedAt,
+		CreatedAt: obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (t EmailVerificationToken) ToCanonical() (model.EmailVerificationToken, error) {
+	return model.EmailVerificationToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Email:     t.Email,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}, nil
+}
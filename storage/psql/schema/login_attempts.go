@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// LoginAttempts keeps failed login attempts data.
+type LoginAttempts struct {
+	bun.BaseModel `bun:"login_attempts,alias:la"`
+	Kind          string    `bun:"kind,pk"`
+	Subject       string    `bun:"subject,pk"`
+	Failures      int       `bun:"failures,notnull"`
+	LastFailedAt  time.Time `bun:"last_failed_at,notnull"`
+	LockedUntil   time.Time `bun:"locked_until,nullzero"`
+}
+
+// NewLoginAttemptsFromCanonical creates a new LoginAttempts DB object from canonical model.
+func NewLoginAttemptsFromCanonical(obj model.LoginAttempts) LoginAttempts {
+	return LoginAttempts{
+		Kind:         string(obj.Kind),
+		Subject:      obj.Subject,
+		Failures:     obj.Failures,
+		LastFailedAt: obj.LastFailedAt,
+		LockedUntil:  obj.LockedUntil,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (la LoginAttempts) ToCanonical() (model.LoginAttempts, error) {
+	return model.LoginAttempts{
+		LoginAttemptsKey: model.LoginAttemptsKey{
+			Kind:    model.LoginAttemptsKind(la.Kind),
+			Subject: la.Subject,
+		},
+		Failures:     la.Failures,
+		LastFailedAt: la.LastFailedAt,
+		LockedUntil:  la.LockedUntil,
+	}, nil
+}
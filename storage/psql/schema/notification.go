@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type (
+	// NotificationPreference keeps user opt-in to notification type.
+	NotificationPreference struct {
+		bun.BaseModel `bun:"notification_preferences,alias:np"`
+		UserID        uuid.UUID `bun:"user_id,pk,type:uuid"`
+		Type          string    `bun:"type,pk"`
+		Email         bool      `bun:"email,notnull"`
+	}
+
+	// EmailMessage keeps email notification data.
+	EmailMessage struct {
+		bun.BaseModel `bun:"email_messages,alias:em"`
+		ID            uuid.UUID `bun:"id,pk,type:uuid"`
+		EventID       uuid.UUID `bun:"event_id,type:uuid,notnull"`
+		UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
+		Type          string    `bun:"type,notnull"`
+		Recipient     string    `bun:"recipient,notnull"`
+		Subject       string    `bun:"subject,notnull"`
+		Body          string    `bun:"body,notnull"`
+		Status        string    `bun:"status,nullzero,notnull,default:'pending'"`
+		Attempts      int       `bun:"attempts,notnull"`
+		NextAttemptAt time.Time `bun:"next_attempt_at,nullzero,notnull,default:current_timestamp"`
+		LastError     string    `bun:"last_error,nullzero"`
+		SentAt        time.Time `bun:"sent_at,nullzero"`
+		CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+		UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	}
+
+	EmailMessages []EmailMessage
+
+	// Notification keeps in-app notification data.
+	Notification struct {
+		bun.BaseModel `bun:"notifications,alias:n"`
+		ID            uuid.UUID       `bun:"id,pk,type:uuid"`
+		UserID        uuid.UUID       `bun:"user_id,type:uuid,notnull"`
+		EventID       uuid.UUID       `bun:"event_id,type:uuid,notnull"`
+		Type          string          `bun:"type,notnull"`
+		Message       string          `bun:"message,notnull"`
+		Data          json.RawMessage `bun:"data,type:jsonb,notnull"`
+		ReadAt        time.Time       `bun:"read_at,nullzero"`
+		CreatedAt     time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	}
+
+	Notifications []Notification
+)
+
+// NewNotificationPreferencesFromCanonical creates new NotificationPreference DB objects from canonical settings.
+func NewNotificationPreferencesFromCanonical(userID uuid.UUID, obj model.NotificationSettings) []NotificationPreference {
+	dbObjs := make([]NotificationPreference, 0, len(obj.EmailEnabled))
+	for notificationType, enabled := range obj.EmailEnabled {
+		dbObjs = append(dbObjs, NotificationPreference{
+			UserID: userID,
+			Type:   string(notificationType),
+			Email:  enabled,
+		})
+	}
+
+	return dbObjs
+}
+
+// NewEmailMessageFromCanonical creates a new EmailMessage DB object from canonical model.
+func NewEmailMessageFromCanonical(obj model.Email) EmailMessage {
+	return EmailMessage{
+		ID:            obj.ID,
+		EventID:       obj.EventID,
+		UserID:        obj.UserID,
+		Type:          string(obj.Type),
+		Recipient:     obj.Message.To,
+		Subject:       obj.Message.Subject,
+		Body:          obj.Message.Body,
+		Status:        string(obj.Status),
+		Attempts:      obj.Attempts,
+		NextAttemptAt: obj.NextAttemptAt,
+		LastError:     obj.LastError,
+		SentAt:        obj.SentAt,
+		CreatedAt:     obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (e EmailMessage) ToCanonical() (model.Email, error) {
+	return model.Email{
+		ID:      e.ID,
+		EventID: e.EventID,
+		UserID:  e.UserID,
+		Type:    model.NotificationType(e.Type),
+		Message: model.Message{
+			To:      e.Recipient,
+			Subject: e.Subject,
+			Body:    e.Body,
+		},
+		Status:        model.EmailStatus(e.Status),
+		Attempts:      e.Attempts,
+		NextAttemptAt: e.NextAttemptAt,
+		LastError:     e.LastError,
+		SentAt:        e.SentAt,
+		CreatedAt:     e.CreatedAt,
+	}, nil
+}
+
+// ToCanonical converts list of DB objects to list of canonical models.
+func (e EmailMessages) ToCanonical() ([]model.Email, error) {
+	objs := make([]model.Email, 0, len(e))
+	for _, dbObj := range e {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// NewNotificationFromCanonical creates a new Notification DB object from canonical model.
+func NewNotificationFromCanonical(obj model.Notification) Notification {
+	return Notification{
+		ID:        obj.ID,
+		UserID:    obj.UserID,
+		EventID:   obj.EventID,
+		Type:      string(obj.Type),
+		Message:   obj.Message,
+		Data:      obj.Data,
+		ReadAt:    obj.ReadAt,
+		CreatedAt: obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (n Notification) ToCanonical() (model.Notification, error) {
+	return model.Notification{
+		ID:        n.ID,
+		UserID:    n.UserID,
+		EventID:   n.EventID,
+		Type:      model.EventType(n.Type),
+		Message:   n.Message,
+		Data:      n.Data,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}, nil
+}
+
+// ToCanonical converts list of DB objects to list of canonical models.
+func (n Notifications) ToCanonical() ([]model.Notification, error) {
+	objs := make([]model.Notification, 0, len(n))
+	for _, dbObj := range n {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
@@ -12,15 +12,16 @@ import (
 type (
 	// Order keeps order data.
 	Order struct {
-		bun.BaseModel `bun:"orders,alias:o"`
-		ID            uuid.UUID `bun:"id,pk,type:uuid"`
-		UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
-		Number        string    `bun:"number,unique,notnull"`
-		Status        string    `bun:"status,nullzero,notnull,default:'NEW'"`
-		Accrual       int       `bun:"accrual,notnull"`
-		UploadedAt    time.Time `bun:"uploaded_at,nullzero,notnull,default:current_timestamp"`
-		UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
-		Updated       bool      `bun:"updated,scanonly"`
+		bun.BaseModel  `bun:"orders,alias:o"`
+		ID             uuid.UUID `bun:"id,pk,type:uuid"`
+		UserID         uuid.UUID `bun:"user_id,type:uuid,notnull"`
+		Number         string    `bun:"number,unique,notnull"`
+		Status         string    `bun:"status,nullzero,notnull,default:'NEW'"`
+		Accrual        int       `bun:"accrual,notnull"`
+		UploadedAt     time.Time `bun:"uploaded_at,nullzero,notnull,default:current_timestamp"`
+		UpdatedAt      time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+		Updated        bool      `bun:"updated,scanonly"`
+		PreviousStatus string    `bun:"previous_status,scanonly"`
 	}
 
 	Orders []Order
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type (
+	// OutboxEvent keeps domain event data.
+	OutboxEvent struct {
+		bun.BaseModel           `bun:"outbox_events,alias:oe"`
+		ID                      uuid.UUID       `bun:"id,pk,type:uuid"`
+		Seq                     int64           `bun:"seq,nullzero,notnull"`
+		Type                    string          `bun:"type,notnull"`
+		Payload                 json.RawMessage `bun:"payload,type:jsonb,notnull"`
+		CreatedAt               time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+		WebhooksEnqueuedAt      time.Time       `bun:"webhooks_enqueued_at,nullzero"`
+		PublishedAt             time.Time       `bun:"published_at,nullzero"`
+		PublishAttempts         int             `bun:"publish_attempts,notnull"`
+		PublishNextAttemptAt    time.Time       `bun:"publish_next_attempt_at,nullzero,notnull,default:current_timestamp"`
+		PublishLastError        string          `bun:"publish_last_error,nullzero"`
+		PublishDeadAt           time.Time       `bun:"publish_dead_at,nullzero"`
+		NotificationsEnqueuedAt time.Time       `bun:"notifications_enqueued_at,nullzero"`
+		InboxEnqueuedAt         time.Time       `bun:"inbox_enqueued_at,nullzero"`
+	}
+
+	OutboxEvents []OutboxEvent
+)
+
+// NewOutboxEvent creates a new OutboxEvent DB object with JSON encoded payload.
+func NewOutboxEvent(eventType model.EventType, payload interface{}) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		Type:    string(eventType),
+		Payload: data,
+	}, nil
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (e OutboxEvent) ToCanonical() (model.Event, error) {
+	return model.Event{
+		ID:        e.ID,
+		Type:      model.EventType(e.Type),
+		Payload:   e.Payload,
+		CreatedAt: e.CreatedAt,
+	}, nil
+}
+
+// ToCanonical converts list of DB objects to list of canonical models.
+func (e OutboxEvents) ToCanonical() ([]model.Event, error) {
+	objs := make([]model.Event, 0, len(e))
+	for _, dbObj := range e {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
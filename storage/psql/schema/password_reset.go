@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// PasswordResetToken keeps password reset token data.
+type PasswordResetToken struct {
+	bun.BaseModel `bun:"password_reset_tokens,alias:prt"`
+	ID            uuid.UUID `bun:"id,pk,type:uuid"`
+	UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
+	TokenHash     string    `bun:"token_hash,unique,notnull"`
+	ExpiresAt     time.Time `bun:"expires_at,notnull"`
+	UsedAt        time.Time `bun:"used_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// NewPasswordResetTokenFromCanonical creates a new PasswordResetToken DB object from canonical model.
+func NewPasswordResetTokenFromCanonical(obj model.PasswordResetToken) PasswordResetToken {
+	return PasswordResetToken{
+		ID:        obj.ID,
+		UserID:    obj.UserID,
+		TokenHash: HashToken(obj.Token),
+		ExpiresAt: obj.ExpiresAt,
+		UsedAt:    obj.UsedAt,
+		CreatedAt: obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (t PasswordResetToken) ToCanonical() (model.PasswordResetToken, error) {
+	return model.PasswordResetToken{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type (
+	// Session keeps refresh token session data.
+	Session struct {
+		bun.BaseModel    `bun:"sessions,alias:s"`
+		ID               uuid.UUID `bun:"id,pk,type:uuid"`
+		UserID           uuid.UUID `bun:"user_id,type:uuid,notnull"`
+		RefreshTokenHash string    `bun:"refresh_token_hash,unique,notnull"`
+		IP               string    `bun:"ip,notnull"`
+		UserAgent        string    `bun:"user_agent,notnull"`
+		ExpiresAt        time.Time `bun:"expires_at,notnull"`
+		RevokedAt        time.Time `bun:"revoked_at,nullzero"`
+		CreatedAt        time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+		UpdatedAt        time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	}
+
+	// RevokedToken keeps revoked access token data.
+	RevokedToken struct {
+		bun.BaseModel `bun:"revoked_tokens,alias:rt"`
+		JTI           string    `bun:"jti,pk"`
+		ExpiresAt     time.Time `bun:"expires_at,notnull"`
+	}
+)
+
+// HashToken returns token SHA-256 hash, so tokens are never stored as is.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// NewSessionFromCanonical creates a new Session DB object from canonical model.
+func NewSessionFromCanonical(obj model.Session) Session {
+	return Session{
+		ID:               obj.ID,
+		UserID:           obj.UserID,
+		RefreshTokenHash: HashToken(obj.RefreshToken),
+		IP:               obj.IP,
+		UserAgent:        obj.UserAgent,
+		ExpiresAt:        obj.ExpiresAt,
+		RevokedAt:        obj.RevokedAt,
+		CreatedAt:        obj.CreatedAt,
+		UpdatedAt:        obj.UpdatedAt,
+	}
+}
+
+// ToCanonical converts a Session DB object to canonical model.
+func (s Session) ToCanonical() (model.Session, error) {
+	return model.Session{
+		ID:        s.ID,
+		UserID:    s.UserID,
+		IP:        s.IP,
+		UserAgent: s.UserAgent,
+		ExpiresAt: s.ExpiresAt,
+		RevokedAt: s.RevokedAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+// NewRevokedTokenFromCanonical creates a new RevokedToken DB object from canonical model.
+func NewRevokedTokenFromCanonical(obj model.AccessToken) RevokedToken {
+	return RevokedToken{
+		JTI:       obj.ID,
+		ExpiresAt: obj.ExpiresAt,
+	}
+}
+
+// NewRevokedTokenFromTwoFactorChallenge creates a new RevokedToken DB object of consumed two-factor challenge.
+func NewRevokedTokenFromTwoFactorChallenge(obj model.TwoFactorChallenge) RevokedToken {
+	return RevokedToken{
+		JTI:       obj.ID,
+		ExpiresAt: obj.ExpiresAt,
+	}
+}
@@ -15,9 +15,11 @@ type (
 		bun.BaseModel `bun:"transactions,alias:t"`
 		ID            uuid.UUID `bun:"id,pk,type:uuid"`
 		UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
-		Order         string    `bun:"order,unique,notnull"`
+		Order         string    `bun:"order,unique,nullzero"`
 		Accrual       int       `bun:"accrual,notnull"`
 		Withdrawal    int       `bun:"withdrawal,notnull"`
+		OperatorID    uuid.UUID `bun:"operator_id,type:uuid,nullzero"`
+		Reason        string    `bun:"reason,nullzero"`
 		ProcessedAt   time.Time `bun:"processed_at,nullzero,notnull,default:current_timestamp"`
 	}
 
@@ -27,10 +29,13 @@ type (
 // NewTransactionFromCanonical creates a new Transaction DB object from canonical model.
 func NewTransactionFromCanonical(obj model.Transaction) Transaction {
 	return Transaction{
+		ID:         obj.ID,
 		UserID:     obj.UserID,
 		Order:      obj.Order,
 		Accrual:    int(obj.Accrual * 100),
 		Withdrawal: int(obj.Withdrawal * 100),
+		OperatorID: obj.OperatorID,
+		Reason:     obj.Reason,
 	}
 }
 
@@ -47,10 +52,13 @@ func NewTransactionsFromCanonical(objs []model.Transaction) []Transaction {
 // ToCanonical converts a Order DB object to canonical model.
 func (o Transaction) ToCanonical() (model.Transaction, error) {
 	return model.Transaction{
+		ID:          o.ID,
 		UserID:      o.UserID,
 		Order:       o.Order,
 		Accrual:     float32(o.Accrual) / 100,
 		Withdrawal:  float32(o.Withdrawal) / 100,
+		OperatorID:  o.OperatorID,
+		Reason:      o.Reason,
 		ProcessedAt: o.ProcessedAt,
 	}, nil
 }
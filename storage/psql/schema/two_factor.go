@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+// TwoFactor keeps user TOTP two-factor authentication data.
+type TwoFactor struct {
+	bun.BaseModel `bun:"user_totp,alias:ut"`
+	UserID        uuid.UUID `bun:"user_id,pk,type:uuid"`
+	Secret        string    `bun:"secret,notnull"`
+	LastUsedStep  int64     `bun:"last_used_step,notnull"`
+	ConfirmedAt   time.Time `bun:"confirmed_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// NewTwoFactorFromCanonical creates a new TwoFactor DB object from canonical model.
+func NewTwoFactorFromCanonical(obj model.TwoFactor) TwoFactor {
+	return TwoFactor{
+		UserID:       obj.UserID,
+		Secret:       obj.Secret,
+		LastUsedStep: obj.LastUsedStep,
+		ConfirmedAt:  obj.ConfirmedAt,
+		CreatedAt:    obj.CreatedAt,
+	}
+}
+
+// ToCanonical converts a DB object to canonical model.
+func (t TwoFactor) ToCanonical() (model.TwoFactor, error) {
+	return model.TwoFactor{
+		UserID:       t.UserID,
+		Secret:       t.Secret,
+		LastUsedStep: t.LastUsedStep,
+		ConfirmedAt:  t.ConfirmedAt,
+		CreatedAt:    t.CreatedAt,
+	}, nil
+}
+
+// RecoveryCode keeps two-factor authentication recovery code data.
+type RecoveryCode struct {
+	bun.BaseModel `bun:"totp_recovery_codes,alias:trc"`
+	ID            uuid.UUID `bun:"id,pk,type:uuid"`
+	UserID        uuid.UUID `bun:"user_id,type:uuid,notnull"`
+	CodeHash      string    `bun:"code_hash,notnull"`
+	UsedAt        time.Time `bun:"used_at,nullzero"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// NewRecoveryCodes creates RecoveryCode DB objects of given user codes.
+func NewRecoveryCodes(userID uuid.UUID, codes []string) []RecoveryCode {
+	objs := make([]RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		objs = append(objs, RecoveryCode{
+			UserID:   userID,
+			CodeHash: HashToken(code),
+		})
+	}
+
+	return objs
+}
@@ -1,67 +1,101 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/vstdy/gophermart/model"
 	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/pkg/passhash"
 )
 
 // User keeps user data.
 type User struct {
-	bun.BaseModel `bun:"users,alias:u"`
-	ID            uuid.UUID `bun:"id,pk"`
-	Login         string    `bun:"login,unique,notnull"`
-	Password      string    `bun:"password,notnull"`
-	CreatedAt     time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt     time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
-	DeletedAt     time.Time `bun:"deleted_at,nullzero,soft_delete"`
+	bun.BaseModel   `bun:"users,alias:u"`
+	ID              uuid.UUID `bun:"id,pk"`
+	Login           string    `bun:"login,unique,notnull"`
+	Password        string    `bun:"password,notnull"`
+	Role            string    `bun:"role,nullzero,notnull,default:'user'"`
+	Email           string    `bun:"email,nullzero"`
+	EmailVerifiedAt time.Time `bun:"email_verified_at,nullzero"`
+	DisplayName     string    `bun:"display_name,nullzero"`
+	Phone           string    `bun:"phone,nullzero"`
+	Birthday        time.Time `bun:"birthday,type:date,nullzero"`
+	Language        string    `bun:"language,nullzero"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt       time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	DeletedAt       time.Time `bun:"deleted_at,nullzero,soft_delete"`
 }
 
-func (u *User) EncryptPassword() error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+// EncryptPassword replaces user password with its hash.
+func (u *User) EncryptPassword(hasher *passhash.Hasher) error {
+	hash, err := hasher.Hash(u.Password)
 	if err != nil {
 		return fmt.Errorf("encrypting password: %w", err)
 	}
-	u.Password = string(hash)
+	u.Password = hash
 
 	return nil
 }
 
-func (u *User) ComparePasswords(password string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
+// ComparePasswords checks password against user password hash.
+// It also reports whether the hash should be upgraded to current hashing algorithm (parameters).
+func (u *User) ComparePasswords(hasher *passhash.Hasher, password string) (bool, error) {
+	rehash, err := hasher.Verify(u.Password, password)
 	if err != nil {
-		return pkg.ErrWrongCredentials
+		// Deleted users have empty password hash of unknown format
+		if errors.Is(err, passhash.ErrMismatch) || errors.Is(err, passhash.ErrUnknownFormat) {
+			return false, pkg.ErrWrongCredentials
+		}
+		return false, fmt.Errorf("comparing passwords: %w", err)
 	}
 
-	return nil
+	return rehash, nil
+}
+
+// AnonymizedLogin returns login deleted user's login is replaced with.
+func AnonymizedLogin(userID uuid.UUID) string {
+	return "deleted-" + userID.String()
 }
 
 // NewUserFromCanonical creates a new User DB object from canonical model.
 func NewUserFromCanonical(obj model.User) User {
 	return User{
-		ID:        obj.ID,
-		Login:     obj.Login,
-		Password:  obj.Password,
-		CreatedAt: obj.CreatedAt,
-		UpdatedAt: obj.UpdatedAt,
-		DeletedAt: obj.DeletedAt,
+		ID:              obj.ID,
+		Login:           obj.Login,
+		Password:        obj.Password,
+		Role:            string(obj.Role),
+		Email:           obj.Email,
+		EmailVerifiedAt: obj.EmailVerifiedAt,
+		DisplayName:     obj.DisplayName,
+		Phone:           obj.Phone,
+		Birthday:        obj.Birthday,
+		Language:        obj.Language,
+		CreatedAt:       obj.CreatedAt,
+		UpdatedAt:       obj.UpdatedAt,
+		DeletedAt:       obj.DeletedAt,
 	}
 }
 
 // ToCanonical converts a DB object to canonical model.
 func (u User) ToCanonical() (model.User, error) {
 	return model.User{
-		ID:        u.ID,
-		Login:     u.Login,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
-		DeletedAt: u.DeletedAt,
+		ID:              u.ID,
+		Login:           u.Login,
+		Password:        u.Password,
+		Role:            model.Role(u.Role),
+		Email:           u.Email,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		DisplayName:     u.DisplayName,
+		Phone:           u.Phone,
+		Birthday:        u.Birthday,
+		Language:        u.Language,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+		DeletedAt:       u.DeletedAt,
 	}, nil
 }
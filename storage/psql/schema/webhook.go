@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+)
+
+type (
+	// WebhookDelivery keeps event delivery to webhook subscription data.
+	WebhookDelivery struct {
+		bun.BaseModel  `bun:"webhook_deliveries,alias:wd"`
+		ID             uuid.UUID    `bun:"id,pk,type:uuid"`
+		EventID        uuid.UUID    `bun:"event_id,type:uuid,notnull"`
+		Event          *OutboxEvent `bun:"rel:belongs-to,join:event_id=id"`
+		Webhook        string       `bun:"webhook,notnull"`
+		Status         string       `bun:"status,nullzero,notnull,default:'pending'"`
+		Attempts       int          `bun:"attempts,notnull"`
+		NextAttemptAt  time.Time    `bun:"next_attempt_at,nullzero,notnull,default:current_timestamp"`
+		LastStatusCode int          `bun:"last_status_code,nullzero"`
+		LastError      string       `bun:"last_error,nullzero"`
+		DeliveredAt    time.Time    `bun:"delivered_at,nullzero"`
+		CreatedAt      time.Time    `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+		UpdatedAt      time.Time    `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	}
+
+	WebhookDeliveries []WebhookDelivery
+)
+
+// ToCanonical converts a DB object to canonical model.
+func (d WebhookDelivery) ToCanonical() (model.WebhookDelivery, error) {
+	obj := model.WebhookDelivery{
+		ID:             d.ID,
+		Webhook:        d.Webhook,
+		Status:         model.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+
+	if d.Event != nil {
+		event, err := d.Event.ToCanonical()
+		if err != nil {
+			return model.WebhookDelivery{}, err
+		}
+		obj.Event = event
+	} else {
+		obj.Event.ID = d.EventID
+	}
+
+	return obj, nil
+}
+
+// ToCanonical converts list of DB objects to list of canonical models.
+func (d WebhookDeliveries) ToCanonical() ([]model.WebhookDelivery, error) {
+	objs := make([]model.WebhookDelivery, 0, len(d))
+	for _, dbObj := range d {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,233 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const (
+	sessionTableName      = "session"
+	revokedTokenTableName = "revoked_token"
+)
+
+// CreateSession adds given session to storage.
+func (st *Storage) CreateSession(ctx context.Context, obj model.Session) (model.Session, error) {
+	logger := st.Logger(ctx, withTable(sessionTableName), withOperation("insert"))
+
+	dbObj := schema.NewSessionFromCanonical(obj)
+
+	_, err := st.db.NewInsert().
+		Model(&dbObj).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.Session{}, err
+	}
+
+	// Expired sessions are of no use anymore
+	_, err = st.db.NewDelete().
+		Model((*schema.Session)(nil)).
+		Where("user_id = ?", dbObj.UserID).
+		Where("expires_at < NOW()").
+		Exec(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Deleting expired sessions")
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.Session{}, err
+	}
+	addedObj.RefreshToken = obj.RefreshToken
+
+	logger.Info().Msgf("Session created %s", addedObj.ID)
+
+	return addedObj, nil
+}
+
+// RotateSession replaces active session refresh token with the one from given session.
+func (st *Storage) RotateSession(ctx context.Context, refreshToken string, obj model.Session) (model.Session, error) {
+	dbObj := schema.NewSessionFromCanonical(obj)
+
+	res, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("refresh_token_hash = ?", dbObj.RefreshTokenHash).
+		Set("expires_at = ?", dbObj.ExpiresAt).
+		Set("ip = ?", dbObj.IP).
+		Set("user_agent = ?", dbObj.UserAgent).
+		Set("updated_at = NOW()").
+		Where("refresh_token_hash = ?", schema.HashToken(refreshToken)).
+		Where("revoked_at IS NULL").
+		Where("expires_at > NOW()").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.Session{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return model.Session{}, pkg.ErrInvalidToken
+	}
+
+	rotatedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.Session{}, err
+	}
+	rotatedObj.RefreshToken = obj.RefreshToken
+
+	return rotatedObj, nil
+}
+
+// RevokeSession revokes user session by its refresh token.
+// Returns pkg.ErrNotFound if there is no such user session, pkg.ErrInvalidToken if it is already revoked.
+func (st *Storage) RevokeSession(ctx context.Context, userID uuid.UUID, refreshToken string) error {
+	logger := st.Logger(ctx, withTable(sessionTableName), withOperation("revoke"))
+
+	var dbObj schema.Session
+
+	res, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("revoked_at = NOW()").
+		Set("updated_at = NOW()").
+		Where("refresh_token_hash = ?", schema.HashToken(refreshToken)).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		exists, err := st.db.NewSelect().
+			Model((*schema.Session)(nil)).
+			Where("refresh_token_hash = ?", schema.HashToken(refreshToken)).
+			Where("user_id = ?", userID).
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return pkg.ErrNotFound
+		}
+
+		return pkg.ErrInvalidToken
+	}
+
+	logger.Info().Msgf("Session revoked %s", dbObj.ID)
+
+	return nil
+}
+
+// GetSessions gets active user sessions, most recently used first.
+func (st *Storage) GetSessions(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	var dbObjs []schema.Session
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > NOW()").
+		Order("updated_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]model.Session, 0, len(dbObjs))
+	for _, dbObj := range dbObjs {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// RevokeUserSession revokes active user session by its id.
+func (st *Storage) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	logger := st.Logger(ctx, withTable(sessionTableName), withOperation("revoke"))
+
+	res, err := st.db.NewUpdate().
+		Model((*schema.Session)(nil)).
+		Set("revoked_at = NOW()").
+		Set("updated_at = NOW()").
+		Where("id = ?", sessionID).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > NOW()").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return pkg.ErrNotFound
+	}
+
+	logger.Info().Msgf("Session revoked %s", sessionID)
+
+	return nil
+}
+
+// IsSessionActive checks whether session exists and isn't revoked or expired.
+func (st *Storage) IsSessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return st.db.NewSelect().
+		Model((*schema.Session)(nil)).
+		Where("id = ?", sessionID).
+		Where("revoked_at IS NULL").
+		Where("expires_at > NOW()").
+		Exists(ctx)
+}
+
+// RevokeAccessToken adds given access token to revoked ones until it expires.
+func (st *Storage) RevokeAccessToken(ctx context.Context, obj model.AccessToken) error {
+	logger := st.Logger(ctx, withTable(revokedTokenTableName), withOperation("insert"))
+
+	dbObj := schema.NewRevokedTokenFromCanonical(obj)
+
+	_, err := st.db.NewInsert().
+		Model(&dbObj).
+		On("CONFLICT (\"jti\") DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Expired tokens are rejected anyway
+	_, err = st.db.NewDelete().
+		Model((*schema.RevokedToken)(nil)).
+		Where("expires_at < NOW()").
+		Exec(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Deleting expired revoked tokens")
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked checks whether given access token is revoked.
+func (st *Storage) IsAccessTokenRevoked(ctx context.Context, obj model.AccessToken) (bool, error) {
+	return st.db.NewSelect().
+		Model((*schema.RevokedToken)(nil)).
+		Where("jti = ?", obj.ID).
+		Exists(ctx)
+}
+
+// revokeUserSessions revokes all active user sessions.
+func revokeUserSessions(ctx context.Context, db bun.IDB, userID uuid.UUID) error {
+	_, err := db.NewUpdate().
+		Model((*schema.Session)(nil)).
+		Set("revoked_at = NOW()").
+		Set("updated_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+
+	return err
+}
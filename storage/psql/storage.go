@@ -7,11 +7,14 @@ import (
 	"runtime"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
 	"github.com/uptrace/bun/migrate"
 
+	"github.com/vstdy/gophermart/pkg/metrics"
+	"github.com/vstdy/gophermart/pkg/passhash"
 	inter "github.com/vstdy/gophermart/storage"
 	"github.com/vstdy/gophermart/storage/psql/migrations"
 	"github.com/vstdy/gophermart/storage/psql/schema"
@@ -33,6 +36,7 @@ type (
 
 		config Config
 		db     *bun.DB
+		hasher *passhash.Hasher
 	}
 
 	// StorageOption defines functional argument for Storage constructor.
@@ -48,6 +52,15 @@ func WithConfig(config Config) StorageOption {
 	}
 }
 
+// WithPasswordHasher sets password hasher.
+func WithPasswordHasher(hasher *passhash.Hasher) StorageOption {
+	return func(st *Storage) error {
+		st.hasher = hasher
+
+		return nil
+	}
+}
+
 // New creates a new psql Storage with custom options.
 func New(opts ...StorageOption) (*Storage, error) {
 	st := &Storage{
@@ -63,6 +76,14 @@ func New(opts ...StorageOption) (*Storage, error) {
 		return nil, fmt.Errorf("config validation: %w", err)
 	}
 
+	if st.hasher == nil {
+		hasher, err := passhash.NewHasher(passhash.NewDefaultConfig())
+		if err != nil {
+			return nil, fmt.Errorf("building password hasher: %w", err)
+		}
+		st.hasher = hasher
+	}
+
 	sqlDB := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(st.config.URI)))
 
 	maxOpenConnections := 4 * runtime.GOMAXPROCS(0)
@@ -79,6 +100,10 @@ func New(opts ...StorageOption) (*Storage, error) {
 		return nil, fmt.Errorf("ping for URI (%s) failed: %w", st.config.URI, err)
 	}
 
+	if err := metrics.Register(collectors.NewDBStatsCollector(sqlDB, serviceName)); err != nil {
+		return nil, fmt.Errorf("registering DB stats collector: %w", err)
+	}
+
 	return st, nil
 }
 
@@ -91,9 +116,35 @@ func (st *Storage) Close() error {
 	return st.db.Close()
 }
 
+// Ping checks DB connectivity.
+func (st *Storage) Ping(ctx context.Context) error {
+	return st.db.PingContext(ctx)
+}
+
+// CheckMigrations checks that all migrations are applied.
+func (st *Storage) CheckMigrations(ctx context.Context) error {
+	ms, err := migrations.GetMigrations()
+	if err != nil {
+		return err
+	}
+
+	migration := migrate.NewMigrator(st.db, ms)
+
+	msWithStatus, err := migration.MigrationsWithStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("getting migrations status: %w", err)
+	}
+
+	if unapplied := msWithStatus.Unapplied(); len(unapplied) > 0 {
+		return fmt.Errorf("unapplied migrations: %s", unapplied.String())
+	}
+
+	return nil
+}
+
 // Migrate performs DB migrations.
 func (st *Storage) Migrate(ctx context.Context) error {
-	logger := st.Logger(withOperation("migration"))
+	logger := st.Logger(ctx, withOperation("migration"))
 
 	ms, err := migrations.GetMigrations()
 	if err != nil {
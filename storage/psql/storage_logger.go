@@ -1,8 +1,9 @@
 package psql
 
 import (
+	"context"
+
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"github.com/vstdy/gophermart/pkg/logging"
 )
@@ -24,9 +25,9 @@ func withOperation(opID string) loggerOption {
 	}
 }
 
-// Logger returns logger with service context.
-func (st *Storage) Logger(opts ...loggerOption) zerolog.Logger {
-	logCtx := log.With().Str(logging.ServiceKey, serviceName)
+// Logger returns request scoped logger with service context.
+func (st *Storage) Logger(ctx context.Context, opts ...loggerOption) zerolog.Logger {
+	logCtx := zerolog.Ctx(ctx).With().Str(logging.ServiceKey, serviceName)
 	for _, opt := range opts {
 		logCtx = opt(logCtx)
 	}
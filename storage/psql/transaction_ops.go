@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/driver/pgdriver"
 
 	"github.com/vstdy/gophermart/model"
@@ -15,6 +16,7 @@ import (
 const transactionTableName = "transaction"
 
 // GetBalance gets current user balance.
+// Debit adjustments decrease current balance, but aren't counted as withdrawn.
 func (st *Storage) GetBalance(ctx context.Context, userID uuid.UUID) (float32, float32, error) {
 	var dbObj schema.Transaction
 	var dbCurrent int
@@ -22,7 +24,8 @@ func (st *Storage) GetBalance(ctx context.Context, userID uuid.UUID) (float32, f
 
 	err := st.db.NewSelect().
 		Model(&dbObj).
-		ColumnExpr("sum(accrual) - sum(withdrawal) AS current, sum(withdrawal) AS used").
+		ColumnExpr("sum(accrual) - sum(withdrawal) AS current").
+		ColumnExpr("coalesce(sum(withdrawal) FILTER (WHERE operator_id IS NULL), 0) AS used").
 		Where("user_id = ?", userID).
 		Scan(ctx, &dbCurrent, &dbUsed)
 	if err != nil {
@@ -35,57 +38,101 @@ func (st *Storage) GetBalance(ctx context.Context, userID uuid.UUID) (float32, f
 	return current, used, nil
 }
 
-// AddAccruals adds accruals.
+// AddAccruals adds accruals and writes points.accrued events to the outbox.
 func (st *Storage) AddAccruals(ctx context.Context, objs []model.Transaction) error {
 	dbObjs := schema.NewTransactionsFromCanonical(objs)
 
-	_, err := st.db.NewInsert().
-		Model(&dbObjs).
-		On("CONFLICT (\"order\") DO UPDATE").
-		Set("accrual = excluded.accrual").
-		Exec(ctx)
-	if err != nil {
-		return err
-	}
+	return st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for i := range dbObjs {
+			dbObj := dbObjs[i]
+			entry, err := st.auditBalanceChange(ctx, tx, model.AuditActionAccrual, dbObj.UserID, func() (string, error) {
+				_, err := tx.NewInsert().
+					Model(&dbObj).
+					On("CONFLICT (\"order\") DO UPDATE").
+					Set("accrual = excluded.accrual").
+					Exec(ctx)
+
+				return dbObj.Order, err
+			})
+			if err != nil {
+				return err
+			}
+
+			err = addOutboxEvent(ctx, tx, model.EventTypePointsAccrued, model.PointsAccruedPayload{
+				Order:   dbObj.Order,
+				UserID:  dbObj.UserID,
+				Amount:  float32(entry.Amount) / 100,
+				Balance: float32(entry.BalanceAfter) / 100,
+			})
+			if err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
-// AddWithdrawal adds withdrawal.
+// AddWithdrawal adds withdrawal and writes points.withdrawn and withdrawal.created events to the outbox.
 func (st *Storage) AddWithdrawal(ctx context.Context, obj model.Transaction) error {
 	st.Lock()
 	defer st.Unlock()
 
 	dbObj := schema.NewTransactionFromCanonical(obj)
-	var enough bool
 
-	err := st.db.NewSelect().
-		Model(&dbObj).
-		ColumnExpr("sum(accrual) - sum(withdrawal) > ? AS enough", dbObj.Withdrawal).
-		Where("user_id = ?", dbObj.UserID).
-		Scan(ctx, &enough)
-	if err != nil {
-		return err
-	}
+	return st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		entry, err := st.auditBalanceChange(ctx, tx, model.AuditActionWithdrawal, dbObj.UserID, func() (string, error) {
+			var enough bool
+			err := tx.NewSelect().
+				Model(&dbObj).
+				ColumnExpr("sum(accrual) - sum(withdrawal) > ? AS enough", dbObj.Withdrawal).
+				Where("user_id = ?", dbObj.UserID).
+				Scan(ctx, &enough)
+			if err != nil {
+				return "", err
+			}
 
-	if !enough {
-		return pkg.ErrNonSufficientFunds
-	}
+			if !enough {
+				return "", pkg.ErrNonSufficientFunds
+			}
 
-	_, err = st.db.NewInsert().
-		Model(&dbObj).
-		Exec(ctx)
-	if err != nil {
-		pgErr := &pgdriver.Error{}
-		if errors.As(err, pgErr) {
-			if pgErr.IntegrityViolation() {
-				return pkg.ErrAlreadyExists
+			_, err = tx.NewInsert().
+				Model(&dbObj).
+				Returning("*").
+				Exec(ctx)
+			if err != nil {
+				pgErr := &pgdriver.Error{}
+				if errors.As(err, pgErr) {
+					if pgErr.IntegrityViolation() {
+						return "", pkg.ErrAlreadyExists
+					}
+				}
+				return "", err
 			}
+
+			return dbObj.Order, nil
+		})
+		if err != nil {
+			return err
 		}
-		return err
-	}
 
-	return nil
+		err = addOutboxEvent(ctx, tx, model.EventTypePointsWithdrawn, model.PointsWithdrawnPayload{
+			Order:   dbObj.Order,
+			UserID:  dbObj.UserID,
+			Amount:  float32(dbObj.Withdrawal) / 100,
+			Balance: float32(entry.BalanceAfter) / 100,
+		})
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, tx, model.EventTypeWithdrawalCreated, model.WithdrawalCreatedPayload{
+			Order:       dbObj.Order,
+			UserID:      dbObj.UserID,
+			Sum:         float32(dbObj.Withdrawal) / 100,
+			ProcessedAt: dbObj.ProcessedAt,
+		})
+	})
 }
 
 // GetWithdrawals gets current user withdrawals.
@@ -96,6 +143,7 @@ func (st *Storage) GetWithdrawals(ctx context.Context, userID uuid.UUID) ([]mode
 		Model(&dbObjs).
 		Where("user_id = ?", userID).
 		Where("withdrawal > 0").
+		Where("operator_id IS NULL").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
@@ -111,3 +159,84 @@ func (st *Storage) GetWithdrawals(ctx context.Context, userID uuid.UUID) ([]mode
 
 	return objs, nil
 }
+
+// AddAdjustment adds manual balance adjustment and writes points.adjusted event to the outbox,
+// debits exceeding current balance are rejected.
+func (st *Storage) AddAdjustment(ctx context.Context, obj model.Transaction) (model.Transaction, error) {
+	logger := st.Logger(ctx, withTable(transactionTableName), withOperation("adjust"))
+
+	st.Lock()
+	defer st.Unlock()
+
+	dbObj := schema.NewTransactionFromCanonical(obj)
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		entry, err := st.auditBalanceChange(ctx, tx, model.AuditActionAdjustment, dbObj.UserID, func() (string, error) {
+			if dbObj.Withdrawal > 0 {
+				var enough bool
+				err := tx.NewSelect().
+					Model((*schema.Transaction)(nil)).
+					ColumnExpr("coalesce(sum(accrual) - sum(withdrawal), 0) >= ? AS enough", dbObj.Withdrawal).
+					Where("user_id = ?", dbObj.UserID).
+					Scan(ctx, &enough)
+				if err != nil {
+					return "", err
+				}
+
+				if !enough {
+					return "", pkg.ErrNonSufficientFunds
+				}
+			}
+
+			_, err := tx.NewInsert().
+				Model(&dbObj).
+				Returning("*").
+				Exec(ctx)
+			if err != nil {
+				return "", err
+			}
+
+			return dbObj.ID.String(), nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, tx, model.EventTypePointsAdjusted, model.PointsAdjustedPayload{
+			AdjustmentID: dbObj.ID,
+			UserID:       dbObj.UserID,
+			Amount:       float32(dbObj.Accrual-dbObj.Withdrawal) / 100,
+			Balance:      float32(entry.BalanceAfter) / 100,
+			Reason:       dbObj.Reason,
+		})
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	logger.Info().Msgf("Balance adjusted %s for user %s by operator %s", addedObj.ID, addedObj.UserID, addedObj.OperatorID)
+
+	return addedObj, nil
+}
+
+// GetAdjustments gets user manual balance adjustments, latest first.
+func (st *Storage) GetAdjustments(ctx context.Context, userID uuid.UUID) ([]model.Transaction, error) {
+	var dbObjs schema.Transactions
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("user_id = ?", userID).
+		Where("operator_id IS NOT NULL").
+		Order("processed_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
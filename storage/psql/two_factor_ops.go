@@ -0,0 +1,247 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/pkg"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const twoFactorTableName = "user_totp"
+
+// CreateTwoFactor adds given unconfirmed two-factor authentication to storage,
+// previous unconfirmed enrolment of the user is replaced.
+func (st *Storage) CreateTwoFactor(ctx context.Context, obj model.TwoFactor) (model.TwoFactor, error) {
+	logger := st.Logger(ctx, withTable(twoFactorTableName), withOperation("upsert"))
+
+	dbObj := schema.NewTwoFactorFromCanonical(obj)
+
+	res, err := st.db.NewInsert().
+		Model(&dbObj).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("secret = EXCLUDED.secret").
+		Set("last_used_step = EXCLUDED.last_used_step").
+		Set("created_at = EXCLUDED.created_at").
+		Where("ut.confirmed_at IS NULL").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.TwoFactor{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return model.TwoFactor{}, pkg.ErrAlreadyExists
+	}
+
+	addedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.TwoFactor{}, err
+	}
+
+	logger.Info().Msgf("Two-factor authentication enrolment started for user %s", addedObj.UserID)
+
+	return addedObj, nil
+}
+
+// GetTwoFactor gets user two-factor authentication.
+func (st *Storage) GetTwoFactor(ctx context.Context, userID uuid.UUID) (model.TwoFactor, error) {
+	dbObj := schema.TwoFactor{UserID: userID}
+
+	err := st.db.NewSelect().
+		Model(&dbObj).
+		WherePK().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.TwoFactor{}, pkg.ErrNotFound
+		}
+		return model.TwoFactor{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+// ConfirmTwoFactor enables user two-factor authentication marking given time step as used
+// and replaces user recovery codes with the given ones.
+func (st *Storage) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, step int64, recoveryCodes []string) error {
+	logger := st.Logger(ctx, withTable(twoFactorTableName), withOperation("confirm"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model((*schema.TwoFactor)(nil)).
+			Set("confirmed_at = NOW()").
+			Set("last_used_step = ?", step).
+			Where("user_id = ?", userID).
+			Where("confirmed_at IS NULL").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return pkg.ErrNotFound
+		}
+
+		_, err = tx.NewDelete().
+			Model((*schema.RecoveryCode)(nil)).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		dbCodes := schema.NewRecoveryCodes(userID, recoveryCodes)
+		_, err = tx.NewInsert().
+			Model(&dbCodes).
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("Two-factor authentication enabled for user %s", userID)
+
+	return nil
+}
+
+// UseTwoFactorStep marks given time step as used,
+// ErrWrongCredentials is returned if the step (or later one) is already used.
+func (st *Storage) UseTwoFactorStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	res, err := st.db.NewUpdate().
+		Model((*schema.TwoFactor)(nil)).
+		Set("last_used_step = ?", step).
+		Where("user_id = ?", userID).
+		Where("confirmed_at IS NOT NULL").
+		Where("last_used_step < ?", step).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return pkg.ErrWrongCredentials
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes user recovery code, ErrWrongCredentials is returned if there is no such unused code.
+func (st *Storage) UseRecoveryCode(ctx context.Context, userID uuid.UUID, code string) error {
+	logger := st.Logger(ctx, withTable(twoFactorTableName), withOperation("use_recovery_code"))
+
+	res, err := st.db.NewUpdate().
+		Model((*schema.RecoveryCode)(nil)).
+		Set("used_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("code_hash = ?", schema.HashToken(code)).
+		Where("used_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return pkg.ErrWrongCredentials
+	}
+
+	logger.Info().Msgf("Recovery code used by user %s", userID)
+
+	return nil
+}
+
+// ConsumeTwoFactorChallenge marks given challenge as used, ErrInvalidToken is returned if it is already used.
+// Consumed challenges are kept along with revoked access tokens until they expire.
+func (st *Storage) ConsumeTwoFactorChallenge(ctx context.Context, obj model.TwoFactorChallenge) error {
+	dbObj := schema.NewRevokedTokenFromTwoFactorChallenge(obj)
+
+	res, err := st.db.NewInsert().
+		Model(&dbObj).
+		On("CONFLICT (\"jti\") DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return pkg.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// DisableTwoFactor verifies user password and deletes user two-factor authentication and recovery codes.
+func (st *Storage) DisableTwoFactor(ctx context.Context, userID uuid.UUID, password string) error {
+	logger := st.Logger(ctx, withTable(twoFactorTableName), withOperation("delete"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := st.checkUserPassword(ctx, tx, userID, password); err != nil {
+			return err
+		}
+
+		return deleteTwoFactor(ctx, tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("Two-factor authentication disabled for user %s", userID)
+
+	return nil
+}
+
+// GetTwoFactors gets two-factor authentications of all users.
+func (st *Storage) GetTwoFactors(ctx context.Context) ([]model.TwoFactor, error) {
+	var dbObjs []schema.TwoFactor
+
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Order("user_id").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]model.TwoFactor, 0, len(dbObjs))
+	for _, dbObj := range dbObjs {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// ReplaceTwoFactorSecret replaces user two-factor authentication secret if it is still the old one.
+func (st *Storage) ReplaceTwoFactorSecret(ctx context.Context, userID uuid.UUID, oldSecret, newSecret string) error {
+	_, err := st.db.NewUpdate().
+		Model((*schema.TwoFactor)(nil)).
+		Set("secret = ?", newSecret).
+		Where("user_id = ?", userID).
+		Where("secret = ?", oldSecret).
+		Exec(ctx)
+
+	return err
+}
+
+// deleteTwoFactor deletes user two-factor authentication and recovery codes.
+func deleteTwoFactor(ctx context.Context, db bun.IDB, userID uuid.UUID) error {
+	_, err := db.NewDelete().
+		Model((*schema.RecoveryCode)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewDelete().
+		Model((*schema.TwoFactor)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+
+	return err
+}
@@ -3,38 +3,56 @@ package psql
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"database/sql"
 
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/driver/pgdriver"
 	"github.com/vstdy/gophermart/model"
 	"github.com/vstdy/gophermart/pkg"
 	"github.com/vstdy/gophermart/storage/psql/schema"
 )
 
-const userTableName = "user"
+const (
+	userTableName = "user"
+	// loginKeyExpr is an expression logins are unique by (matches users_login_normalized_idx index).
+	loginKeyExpr = "lower(normalize(login, NFKC))"
+)
 
-// CreateUser adds given url objects to storage
+// CreateUser adds given url objects to storage and writes user.registered event to the outbox.
 func (st *Storage) CreateUser(ctx context.Context, rawObj model.User) (model.User, error) {
-	logger := st.Logger(withTable(userTableName), withOperation("insert"))
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("insert"))
 
 	dbObj := schema.NewUserFromCanonical(rawObj)
 
-	if err := dbObj.EncryptPassword(); err != nil {
+	if err := dbObj.EncryptPassword(st.hasher); err != nil {
 		return model.User{}, err
 	}
 
-	_, err := st.db.NewInsert().
-		Model(&dbObj).
-		Returning("*").
-		Exec(ctx)
-	if err != nil {
-		pgErr := &pgdriver.Error{}
-		if errors.As(err, pgErr) {
-			if pgErr.IntegrityViolation() {
-				return model.User{}, pkg.ErrAlreadyExists
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(&dbObj).
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			pgErr := &pgdriver.Error{}
+			if errors.As(err, pgErr) {
+				if pgErr.IntegrityViolation() {
+					return pkg.ErrAlreadyExists
+				}
 			}
+			return err
 		}
+
+		return addOutboxEvent(ctx, tx, model.EventTypeUserRegistered, model.UserRegisteredPayload{
+			UserID:       dbObj.ID,
+			Login:        dbObj.Login,
+			RegisteredAt: dbObj.CreatedAt,
+		})
+	})
+	if err != nil {
 		return model.User{}, err
 	}
 
@@ -50,13 +68,13 @@ func (st *Storage) CreateUser(ctx context.Context, rawObj model.User) (model.Use
 
 // AuthenticateUser verifies the identity of credentials.
 func (st *Storage) AuthenticateUser(ctx context.Context, rawObj model.User) (model.User, error) {
-	logger := st.Logger(withTable(userTableName), withOperation("login"))
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("login"))
 
 	dbObj := schema.NewUserFromCanonical(rawObj)
 
 	err := st.db.NewSelect().
 		Model(&dbObj).
-		WherePK("login").
+		Where(loginKeyExpr+" = lower(?)", dbObj.Login).
 		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -65,10 +83,20 @@ func (st *Storage) AuthenticateUser(ctx context.Context, rawObj model.User) (mod
 		return model.User{}, err
 	}
 
-	if err = dbObj.ComparePasswords(rawObj.Password); err != nil {
+	rehash, err := dbObj.ComparePasswords(st.hasher, rawObj.Password)
+	if err != nil {
 		return model.User{}, err
 	}
 
+	// Password hash is upgraded transparently while the password is known
+	if rehash {
+		if err = st.updatePasswordHash(ctx, &dbObj, rawObj.Password); err != nil {
+			logger.Warn().Err(err).Msg("Upgrading password hash")
+		} else {
+			logger.Info().Msgf("Password hash upgraded for user %s", dbObj.ID)
+		}
+	}
+
 	obj, err := dbObj.ToCanonical()
 	if err != nil {
 		return model.User{}, err
@@ -78,3 +106,315 @@ func (st *Storage) AuthenticateUser(ctx context.Context, rawObj model.User) (mod
 
 	return obj, nil
 }
+
+// VerifyUserPassword checks user password, ErrWrongCredentials is returned if it doesn't match.
+func (st *Storage) VerifyUserPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	dbObj := schema.User{ID: userID}
+
+	err := st.db.NewSelect().
+		Model(&dbObj).
+		WherePK().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pkg.ErrNotFound
+		}
+		return err
+	}
+
+	_, err = dbObj.ComparePasswords(st.hasher, password)
+
+	return err
+}
+
+// GetUser gets user by id.
+func (st *Storage) GetUser(ctx context.Context, userID uuid.UUID) (model.User, error) {
+	dbObj := schema.User{ID: userID}
+
+	err := st.db.NewSelect().
+		Model(&dbObj).
+		WherePK().
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, pkg.ErrNotFound
+		}
+		return model.User{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+// GetUserByLogin gets user by login (case-insensitively).
+func (st *Storage) GetUserByLogin(ctx context.Context, login string) (model.User, error) {
+	var dbObj schema.User
+
+	err := st.db.NewSelect().
+		Model(&dbObj).
+		Where(loginKeyExpr+" = lower(?)", login).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, pkg.ErrNotFound
+		}
+		return model.User{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+// SearchUsers gets users whose login contains query (case-insensitively) ordered by login.
+func (st *Storage) SearchUsers(ctx context.Context, query string, limit, offset int) ([]model.User, error) {
+	var dbObjs []schema.User
+
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query) + "%"
+	err := st.db.NewSelect().
+		Model(&dbObjs).
+		Where("login ILIKE ?", pattern).
+		Order("login").
+		Limit(limit).
+		Offset(offset).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]model.User, 0, len(dbObjs))
+	for _, dbObj := range dbObjs {
+		obj, err := dbObj.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// ChangePassword verifies user current password, sets the new one and revokes user sessions.
+func (st *Storage) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("change_password"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		dbObj := schema.User{ID: userID}
+		err := tx.NewSelect().
+			Model(&dbObj).
+			WherePK().
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.ErrNotFound
+			}
+			return err
+		}
+
+		if _, err = dbObj.ComparePasswords(st.hasher, currentPassword); err != nil {
+			return err
+		}
+
+		return st.setUserPassword(ctx, tx, userID, newPassword)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("User password changed %s", userID)
+
+	return nil
+}
+
+// DeleteUser verifies user password, soft-deletes the user anonymising the login and profile and revokes user sessions.
+// User orders and transactions are kept for accounting.
+func (st *Storage) DeleteUser(ctx context.Context, userID uuid.UUID, password string) error {
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("delete"))
+
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		dbObj := schema.User{ID: userID}
+		err := tx.NewSelect().
+			Model(&dbObj).
+			WherePK().
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return pkg.ErrNotFound
+			}
+			return err
+		}
+
+		if _, err = dbObj.ComparePasswords(st.hasher, password); err != nil {
+			return err
+		}
+
+		// Empty password hash never matches, so the account can't be logged in anymore
+		_, err = tx.NewUpdate().
+			Model(&dbObj).
+			Set("login = ?", schema.AnonymizedLogin(userID)).
+			Set("password = ''").
+			Set("email = NULL").
+			Set("email_verified_at = NULL").
+			Set("display_name = NULL").
+			Set("phone = NULL").
+			Set("birthday = NULL").
+			Set("language = NULL").
+			Set("updated_at = NOW()").
+			Set("deleted_at = NOW()").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*schema.PasswordResetToken)(nil)).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = deleteEmailVerificationTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		if err = deleteTwoFactor(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		if err = deleteUserNotifications(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return revokeUserSessions(ctx, tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("User deleted %s", userID)
+
+	return nil
+}
+
+// SetUserRole sets user role.
+func (st *Storage) SetUserRole(ctx context.Context, userID uuid.UUID, role model.Role) (model.User, error) {
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("set_role"))
+
+	dbObj := schema.User{ID: userID}
+
+	res, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("role = ?", string(role)).
+		Set("updated_at = NOW()").
+		WherePK().
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return model.User{}, err
+	}
+	if affected == 0 {
+		return model.User{}, pkg.ErrNotFound
+	}
+
+	obj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	logger.Info().Msgf("User role set %s: %s", obj.ID, obj.Role)
+
+	return obj, nil
+}
+
+// UpdateProfile sets given user profile fields (clears the empty ones), nil fields are kept unchanged.
+func (st *Storage) UpdateProfile(ctx context.Context, userID uuid.UUID, obj model.ProfileUpdate) (model.User, error) {
+	logger := st.Logger(ctx, withTable(userTableName), withOperation("update_profile"))
+
+	dbObj := schema.User{ID: userID}
+
+	query := st.db.NewUpdate().
+		Model(&dbObj).
+		Set("updated_at = NOW()").
+		WherePK().
+		Returning("*")
+	if obj.DisplayName != nil {
+		query = query.Set("display_name = NULLIF(?, '')", *obj.DisplayName)
+	}
+	if obj.Phone != nil {
+		query = query.Set("phone = NULLIF(?, '')", *obj.Phone)
+	}
+	if obj.Birthday != nil {
+		query = query.Set("birthday = NULLIF(?, '')::date", *obj.Birthday)
+	}
+	if obj.Language != nil {
+		query = query.Set("language = NULLIF(?, '')", *obj.Language)
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return model.User{}, err
+	}
+	if affected == 0 {
+		return model.User{}, pkg.ErrNotFound
+	}
+
+	updatedObj, err := dbObj.ToCanonical()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	logger.Info().Msgf("User profile updated %s", userID)
+
+	return updatedObj, nil
+}
+
+// updatePasswordHash replaces user password hash with the one of current hashing algorithm.
+func (st *Storage) updatePasswordHash(ctx context.Context, dbObj *schema.User, password string) error {
+	oldHash := dbObj.Password
+	dbObj.Password = password
+	if err := dbObj.EncryptPassword(st.hasher); err != nil {
+		dbObj.Password = oldHash
+		return err
+	}
+
+	// Password could be changed concurrently, so the hash is only replaced if it is still the same
+	_, err := st.db.NewUpdate().
+		Model(dbObj).
+		Set("password = ?", dbObj.Password).
+		WherePK().
+		Where("password = ?", oldHash).
+		Exec(ctx)
+
+	return err
+}
+
+// setUserPassword sets user password and revokes all user sessions.
+func (st *Storage) setUserPassword(ctx context.Context, db bun.IDB, userID uuid.UUID, password string) error {
+	dbObj := schema.User{ID: userID, Password: password}
+	if err := dbObj.EncryptPassword(st.hasher); err != nil {
+		return err
+	}
+
+	_, err := db.NewUpdate().
+		Model(&dbObj).
+		Set("password = ?", dbObj.Password).
+		Set("updated_at = NOW()").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return revokeUserSessions(ctx, db, userID)
+}
@@ -0,0 +1,197 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+
+	"github.com/vstdy/gophermart/model"
+	"github.com/vstdy/gophermart/storage/psql/schema"
+)
+
+const webhookDeliveryTableName = "webhook_delivery"
+
+// EnqueueWebhookDeliveries creates deliveries of outbox events not yet enqueued
+// to webhooks subscribed to the event type. Returns the number of deliveries created.
+func (st *Storage) EnqueueWebhookDeliveries(ctx context.Context, subscriptions map[model.EventType][]string, limit int) (int, error) {
+	logger := st.Logger(ctx, withTable(webhookDeliveryTableName), withOperation("enqueue"))
+
+	var created int
+	err := st.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var dbEvents []schema.OutboxEvent
+		err := tx.NewSelect().
+			Model(&dbEvents).
+			Column("id", "type").
+			Where("webhooks_enqueued_at IS NULL").
+			Order("seq").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if len(dbEvents) == 0 {
+			return nil
+		}
+
+		eventIDs := make([]uuid.UUID, 0, len(dbEvents))
+		var dbObjs []schema.WebhookDelivery
+		for _, dbEvent := range dbEvents {
+			eventIDs = append(eventIDs, dbEvent.ID)
+			for _, webhook := range subscriptions[model.EventType(dbEvent.Type)] {
+				dbObjs = append(dbObjs, schema.WebhookDelivery{EventID: dbEvent.ID, Webhook: webhook})
+			}
+		}
+
+		if len(dbObjs) > 0 {
+			res, err := tx.NewInsert().
+				Model(&dbObjs).
+				On("CONFLICT DO NOTHING").
+				Returning("NULL").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			created = int(rows)
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*schema.OutboxEvent)(nil)).
+			Set("webhooks_enqueued_at = NOW()").
+			Where("id IN (?)", bun.In(eventIDs)).
+			Exec(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if created > 0 {
+		logger.Debug().Msgf("Webhook deliveries enqueued: %d", created)
+	}
+
+	return created, nil
+}
+
+// ClaimWebhookDeliveries gets pending deliveries due for an attempt along with their events.
+// Claimed deliveries are postponed by lease, so concurrent dispatchers don't pick them up meanwhile.
+func (st *Storage) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	var ids []uuid.UUID
+
+	dueQuery := st.db.NewSelect().
+		Model((*schema.WebhookDelivery)(nil)).
+		Column("id").
+		Where("status = ?", model.WebhookDeliveryPending).
+		Where("next_attempt_at <= NOW()").
+		Order("next_attempt_at").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	_, err := st.db.NewUpdate().
+		Model((*schema.WebhookDelivery)(nil)).
+		Set("next_attempt_at = ?", time.Now().Add(lease)).
+		Set("updated_at = NOW()").
+		Where("id IN (?)", dueQuery).
+		Returning("id").
+		Exec(ctx, &ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var dbObjs schema.WebhookDeliveries
+	err = st.db.NewSelect().
+		Model(&dbObjs).
+		Relation("Event").
+		Where("wd.id IN (?)", bun.In(ids)).
+		Order("wd.next_attempt_at").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// UpdateWebhookDelivery saves delivery attempt result.
+func (st *Storage) UpdateWebhookDelivery(ctx context.Context, obj model.WebhookDelivery) error {
+	dbObj := schema.WebhookDelivery{
+		ID:             obj.ID,
+		Status:         string(obj.Status),
+		Attempts:       obj.Attempts,
+		NextAttemptAt:  obj.NextAttemptAt,
+		LastStatusCode: obj.LastStatusCode,
+		LastError:      obj.LastError,
+		DeliveredAt:    obj.DeliveredAt,
+		UpdatedAt:      time.Now(),
+	}
+
+	_, err := st.db.NewUpdate().
+		Model(&dbObj).
+		Column("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	return err
+}
+
+// GetWebhookDeliveries gets deliveries with given status (any if empty), latest first.
+func (st *Storage) GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus, limit int) ([]model.WebhookDelivery, error) {
+	var dbObjs schema.WebhookDeliveries
+
+	query := st.db.NewSelect().
+		Model(&dbObjs).
+		Relation("Event").
+		Order("wd.created_at DESC").
+		Limit(limit)
+	if status != "" {
+		query = query.Where("wd.status = ?", status)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return dbObjs.ToCanonical()
+}
+
+// ReplayWebhookDeliveries schedules given deliveries (all dead ones if none given) for immediate delivery
+// with attempts counter reset. Returns the number of deliveries scheduled.
+func (st *Storage) ReplayWebhookDeliveries(ctx context.Context, ids []uuid.UUID) (int, error) {
+	logger := st.Logger(ctx, withTable(webhookDeliveryTableName), withOperation("replay"))
+
+	query := st.db.NewUpdate().
+		Model((*schema.WebhookDelivery)(nil)).
+		Set("status = ?", model.WebhookDeliveryPending).
+		Set("attempts = 0").
+		Set("next_attempt_at = NOW()").
+		Set("updated_at = NOW()")
+	if len(ids) > 0 {
+		query = query.Where("id IN (?)", bun.In(ids))
+	} else {
+		query = query.Where("status = ?", model.WebhookDeliveryDead)
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info().Msgf("Webhook deliveries replayed: %d", rows)
+
+	return int(rows), nil
+}